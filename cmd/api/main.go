@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/em/go-web3/internal/config"
 	"github.com/em/go-web3/internal/ethereum"
 	"github.com/em/go-web3/internal/events"
+	"github.com/em/go-web3/internal/logging"
 )
 
 func main() {
@@ -20,41 +22,73 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Create logger
+	logger := logging.New(&cfg.Log)
+
+	if err := ethereum.SetNumberEncoding(cfg.Server.NumberEncoding); err != nil {
+		logger.Error("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
 	// Create Ethereum client
 	ethClient, err := ethereum.NewClient(&cfg.Ethereum)
 	if err != nil {
-		log.Fatalf("Failed to create Ethereum client: %v", err)
+		logger.Error("Failed to create Ethereum client", "error", err)
+		os.Exit(1)
 	}
 
 	// Create event service
-	eventService := events.NewService(ethClient.Client)
+	eventService := events.NewService(ethClient, &cfg.Events, logger)
+	if cfg.Events.NATSURL != "" {
+		sink, err := events.NewNATSSink(cfg.Events.NATSURL, cfg.Events.NATSSubjectPrefix, logger)
+		if err != nil {
+			logger.Error("Failed to create NATS event sink", "error", err)
+			os.Exit(1)
+		}
+		eventService.AddSink(sink)
+	}
 	if err := eventService.Start(); err != nil {
-		log.Fatalf("Failed to start event service: %v", err)
+		logger.Error("Failed to start event service", "error", err)
+		os.Exit(1)
 	}
+	eventService.WatchAddresses(cfg.Ethereum.WatchAddresses)
 
 	// Create API handler
-	handler := api.NewHandler(ethClient, eventService)
+	handler := api.NewHandler(ethClient, eventService, &cfg.Server, logger)
 
 	// Create and start server
-	server := api.NewServer(&cfg.Server, handler)
+	server, err := api.NewServer(&cfg.Server, handler, logger)
+	if err != nil {
+		logger.Error("Failed to create server", "error", err)
+		os.Exit(1)
+	}
 
-	// Handle graceful shutdown
-	quit := make(chan struct{})
+	serverErr := make(chan error, 1)
 	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
-		<-sigint
-
-		// Stop the event service
-		eventService.Stop()
-
-		close(quit)
+		if err := server.Start(); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
 	}()
 
-	go server.GracefulShutdown(quit)
+	// Wait for a shutdown signal
+	sigint := make(chan os.Signal, 1)
+	signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
 
-	// Start server
-	if err := server.Start(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Server failed to start: %v", err)
+	select {
+	case <-sigint:
+	case err := <-serverErr:
+		logger.Error("Server failed to start", "error", err)
+		eventService.Stop()
+		os.Exit(1)
 	}
+
+	// Shut down in order. WebSocket connections are hijacked and not tracked by
+	// the HTTP server, so they get their close frame first; then in-flight HTTP
+	// requests are drained, and finally the chain subscriptions are stopped.
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	eventService.CloseClients(ctx)
+	server.GracefulShutdown(ctx)
+	eventService.Stop()
 }
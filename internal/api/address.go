@@ -0,0 +1,26 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// parseAddress validates a hex address from a request. Mixed-case addresses
+// must carry a valid EIP-55 checksum; with ?strict=true all addresses must,
+// so all-lowercase input is rejected too.
+func parseAddress(c *gin.Context, s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("invalid address %q", s)
+	}
+
+	address := common.HexToAddress(s)
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	mixedCase := digits != strings.ToLower(digits) && digits != strings.ToUpper(digits)
+	if (mixedCase || c.Query("strict") == "true") && digits != address.Hex()[2:] {
+		return common.Address{}, fmt.Errorf("invalid EIP-55 checksum for address %q", s)
+	}
+	return address, nil
+}
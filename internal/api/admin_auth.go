@@ -0,0 +1,25 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyHeader carries the admin API key
+const apiKeyHeader = "X-API-Key"
+
+// apiKeyMiddleware rejects requests without the API key in the X-API-Key
+// header with 401. An empty key rejects every request.
+func apiKeyMiddleware(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key == "" || subtle.ConstantTimeCompare([]byte(c.GetHeader(apiKeyHeader)), []byte(key)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "invalid or missing API key",
+			})
+			return
+		}
+		c.Next()
+	}
+}
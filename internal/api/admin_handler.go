@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeadLetters handles listing the events that recently failed to be
+// delivered to clients, oldest first and a page at a time
+func (h *Handler) GetDeadLetters(c *gin.Context) {
+	params, err := parsePageParams(c, defaultListLimit, maxListLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	deadLetters, ok := h.eventService.DeadLetters()
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "dead-letter log is disabled, set events.deadLetterSize to enable it",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, paginate(deadLetters, params))
+}
+
+// GetStatus handles the operational status endpoint: connected clients,
+// active subscriptions and filters, and the processing state
+func (h *Handler) GetStatus(c *gin.Context) {
+	status := h.eventService.Status()
+
+	filters := make([]gin.H, 0, len(status.TransactionFilters))
+	for _, entry := range status.TransactionFilters {
+		filters = append(filters, filterResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"clients":               status.Clients,
+		"streams":               status.Streams,
+		"contractSubscriptions": status.ContractSubscriptions,
+		"transactionFilters":    filters,
+		"latestBlock":           status.LatestBlock,
+		"catchUpRemaining":      status.CatchUpRemaining,
+		"queueDepth":            status.QueueDepth,
+		"workers":               status.Workers,
+		"goroutines":            status.Goroutines,
+		"pause":                 status.Pause,
+		"circuitBreaker":        h.ethClient.BreakerState(),
+		"connection":            h.ethClient.ConnectionState(),
+	})
+}
+
+// PauseEvents handles pausing event broadcasting, e.g. while a downstream
+// sink is drained. Node subscriptions keep running meanwhile.
+func (h *Handler) PauseEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, h.eventService.Pause())
+}
+
+// ResumeEvents handles resuming event broadcasting, delivering the events
+// held while paused first
+func (h *Handler) ResumeEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, h.eventService.Resume())
+}
@@ -0,0 +1,431 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+
+	"github.com/em/go-web3/internal/ethereum"
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+)
+
+// ExecuteContractRequest represents a request to call a state-changing contract method
+type ExecuteContractRequest struct {
+	ContractAddress string        `json:"contractAddress" binding:"required"`
+	ABI             string        `json:"abi" binding:"required"`
+	Method          string        `json:"method" binding:"required"`
+	Args            []interface{} `json:"args"`
+	Value           string        `json:"value"` // In wei, optional
+}
+
+// ExecuteContract handles the contract execution endpoint
+func (h *Handler) ExecuteContract(c *gin.Context) {
+	var req ExecuteContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	contract, err := parseAddress(c, req.ContractAddress)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	value := big.NewInt(0)
+	if req.Value != "" {
+		var ok bool
+		value, ok = new(big.Int).SetString(req.Value, 10)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid value format",
+			})
+			return
+		}
+	}
+
+	sent, err := h.ethClient.SubmitContractCall(c.Request.Context(), contract.Hex(), req.ABI, req.Method, value, req.Args...)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"txHash":   sent.Hash,
+		"gasLimit": sent.GasLimit,
+	})
+}
+
+// CallContractRequest represents a request to call a read-only contract method
+type CallContractRequest struct {
+	ContractAddress string        `json:"contractAddress" binding:"required"`
+	ABI             string        `json:"abi" binding:"required"`
+	Method          string        `json:"method" binding:"required"`
+	Args            []interface{} `json:"args"`
+	From            string        `json:"from"` // Caller address, optional (defaults to the service's account)
+}
+
+// CallContract handles the read-only contract call endpoint
+func (h *Handler) CallContract(c *gin.Context) {
+	var req CallContractRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	contract, err := parseAddress(c, req.ContractAddress)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var from *common.Address
+	if req.From != "" {
+		addr, err := parseAddress(c, req.From)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("invalid from address: %v", err),
+			})
+			return
+		}
+		from = &addr
+	}
+
+	result, err := h.ethClient.CallMethod(c.Request.Context(), contract.Hex(), req.ABI, req.Method, from, req.Args...)
+	if err != nil {
+		var revertErr *ethereum.RevertError
+		switch {
+		case errors.As(err, &revertErr):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":  revertErr.Error(),
+				"reason": revertErr.Reason,
+			})
+		case errors.Is(err, ethereum.ErrInvalidABI):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(rpcErrorStatus(err), gin.H{
+				"error": err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"result": result,
+	})
+}
+
+// Create2AddressRequest represents a request to compute a CREATE2 deployment address
+type Create2AddressRequest struct {
+	Deployer     string `json:"deployer" binding:"required"`     // Factory contract executing CREATE2
+	Salt         string `json:"salt" binding:"required"`         // 32 bytes of hex
+	InitCodeHash string `json:"initCodeHash" binding:"required"` // Keccak256 of the creation code with constructor arguments
+}
+
+// ComputeCreate2Address handles computing the address of a CREATE2 deployment
+func (h *Handler) ComputeCreate2Address(c *gin.Context) {
+	var req Create2AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	deployer, err := parseAddress(c, req.Deployer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	salt, err := parseHash32(req.Salt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid salt: " + err.Error(),
+		})
+		return
+	}
+
+	initCodeHash, err := parseHash32(req.InitCodeHash)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid init code hash: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"address":      h.ethClient.ComputeCreate2Address(deployer, salt, initCodeHash).Hex(),
+		"deployer":     deployer.Hex(),
+		"salt":         salt.Hex(),
+		"initCodeHash": initCodeHash.Hex(),
+	})
+}
+
+// parseHash32 parses 32 bytes of 0x-prefixed hex
+func parseHash32(s string) (common.Hash, error) {
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(raw) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("must be 32 bytes, got %d", len(raw))
+	}
+	return common.BytesToHash(raw), nil
+}
+
+// VerifyBytecodeRequest represents a request to check a contract's deployed bytecode
+type VerifyBytecodeRequest struct {
+	Address      string `json:"address" binding:"required"`
+	ExpectedHash string `json:"expectedHash" binding:"required"` // Keccak256 of the runtime bytecode
+}
+
+// VerifyBytecode handles the bytecode verification endpoint
+func (h *Handler) VerifyBytecode(c *gin.Context) {
+	var req VerifyBytecodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	address, err := parseAddress(c, req.Address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	hashBytes, err := hexutil.Decode(req.ExpectedHash)
+	if err != nil || len(hashBytes) != common.HashLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid expected hash: must be 32 bytes of hex",
+		})
+		return
+	}
+	expectedHash := common.BytesToHash(hashBytes)
+
+	matches, err := h.ethClient.VerifyBytecode(c.Request.Context(), address.Hex(), expectedHash)
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrNoCode) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":   err.Error(),
+			"address": address.Hex(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"address":      address.Hex(),
+		"expectedHash": expectedHash.Hex(),
+		"matches":      matches,
+	})
+}
+
+// maxBundleCalls is the largest number of calls accepted in one simulated bundle
+const maxBundleCalls = 20
+
+// BundleCall is a call of a simulated bundle
+type BundleCall struct {
+	From  string `json:"from"` // Defaults to the service's account
+	To    string `json:"to" binding:"required"`
+	Data  string `json:"data"`  // Hex-encoded calldata, optional
+	Value string `json:"value"` // In wei, optional
+	Gas   uint64 `json:"gas"`   // Optional
+}
+
+// SimulateBundleRequest represents a request to simulate a sequence of calls
+type SimulateBundleRequest struct {
+	Calls []BundleCall `json:"calls" binding:"required,min=1,dive"`
+}
+
+// SimulateBundle handles the bundle simulation endpoint. The calls run in
+// order, each on the state left by the previous ones, and nothing is sent.
+func (h *Handler) SimulateBundle(c *gin.Context) {
+	var req SimulateBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if len(req.Calls) > maxBundleCalls {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("at most %d calls are allowed per bundle", maxBundleCalls),
+		})
+		return
+	}
+
+	calls := make([]goethereum.CallMsg, len(req.Calls))
+	for i, call := range req.Calls {
+		msg, err := bundleCallMsg(c, call)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("call %d: %v", i, err),
+			})
+			return
+		}
+		calls[i] = msg
+	}
+
+	results, err := h.ethClient.SimulateBundle(c.Request.Context(), calls)
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrBundleSimulationUnsupported) {
+			status = http.StatusNotImplemented
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	out := make([]gin.H, len(results))
+	allSucceeded := true
+	for i, result := range results {
+		item := gin.H{
+			"index":      i,
+			"success":    result.Success,
+			"returnData": hexutil.Encode(result.ReturnData),
+		}
+		if result.GasUsed != 0 {
+			item["gasUsed"] = result.GasUsed
+		}
+		switch {
+		case result.Revert != nil:
+			item["error"] = result.Revert.Error()
+			item["reason"] = result.Revert.Reason
+		case result.Error != "":
+			item["error"] = result.Error
+		}
+		allSucceeded = allSucceeded && result.Success
+		out[i] = item
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": out,
+		"success": allSucceeded,
+	})
+}
+
+// bundleCallMsg validates a call of a bundle
+func bundleCallMsg(c *gin.Context, call BundleCall) (goethereum.CallMsg, error) {
+	var msg goethereum.CallMsg
+
+	to, err := parseAddress(c, call.To)
+	if err != nil {
+		return msg, err
+	}
+	msg.To = &to
+
+	if call.From != "" {
+		if msg.From, err = parseAddress(c, call.From); err != nil {
+			return msg, fmt.Errorf("invalid from address: %v", err)
+		}
+	}
+	if call.Data != "" {
+		if msg.Data, err = hexutil.Decode(call.Data); err != nil {
+			return msg, fmt.Errorf("invalid data: %v", err)
+		}
+	}
+	if call.Value != "" {
+		value, ok := new(big.Int).SetString(call.Value, 10)
+		if !ok || value.Sign() < 0 {
+			return msg, fmt.Errorf("invalid value format")
+		}
+		msg.Value = value
+	}
+	msg.Gas = call.Gas
+	return msg, nil
+}
+
+// DecodeInputRequest represents a request to decode transaction input
+type DecodeInputRequest struct {
+	Input string `json:"input" binding:"required"` // Hex-encoded calldata
+	ABI   string `json:"abi"`                      // Optional; common methods are recognised without one
+}
+
+// DecodeInput handles the calldata decoding endpoint
+func (h *Handler) DecodeInput(c *gin.Context) {
+	var req DecodeInputRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	input, err := hexutil.Decode(req.Input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("invalid input: %v", err),
+		})
+		return
+	}
+
+	decoded, err := ethereum.DecodeCalldata(input, req.ABI)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, ethereum.ErrUnknownSelector) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, decoded)
+}
+
+// GetProxyImplementation handles the proxy implementation endpoint
+func (h *Handler) GetProxyImplementation(c *gin.Context) {
+	address, err := parseAddress(c, c.Param("address"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	implementation, err := h.ethClient.GetProxyImplementation(c.Request.Context(), address.Hex())
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrNotProxy) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error":   err.Error(),
+			"address": address.Hex(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"address":        address.Hex(),
+		"implementation": implementation.Hex(),
+	})
+}
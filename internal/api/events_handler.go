@@ -1,7 +1,9 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/em/go-web3/internal/events"
@@ -20,6 +22,59 @@ var upgrader = websocket.Upgrader{
 
 // EventsHandler handles WebSocket connections for events
 func (h *Handler) EventsHandler(c *gin.Context) {
+	h.serveWebSocket(c, nil)
+}
+
+// BlocksChannelHandler handles WebSocket connections receiving only new blocks
+func (h *Handler) BlocksChannelHandler(c *gin.Context) {
+	channel := events.BlocksChannel()
+	h.serveWebSocket(c, &channel)
+}
+
+// TransactionsChannelHandler handles WebSocket connections receiving only transactions
+func (h *Handler) TransactionsChannelHandler(c *gin.Context) {
+	channel := events.TransactionsChannel()
+	h.serveWebSocket(c, &channel)
+}
+
+// ContractChannelHandler handles WebSocket connections receiving only the
+// events of the contract in the URL
+func (h *Handler) ContractChannelHandler(c *gin.Context) {
+	contract, err := parseAddress(c, c.Param("address"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !h.eventService.ContractAllowed(contract.Hex()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": events.ErrContractNotAllowed.Error(),
+		})
+		return
+	}
+
+	channel := events.ContractChannel(contract)
+	h.serveWebSocket(c, &channel)
+}
+
+// serveWebSocket upgrades the connection and registers a WebSocket client,
+// restricted to a channel if one is given. With a since query parameter, the
+// buffered events numbered after it are replayed first.
+func (h *Handler) serveWebSocket(c *gin.Context, channel *events.Channel) {
+	var since *uint64
+	if s := c.Query("since"); s != "" {
+		seq, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid since: must be an event sequence number",
+			})
+			return
+		}
+		since = &seq
+	}
+
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -29,10 +84,16 @@ func (h *Handler) EventsHandler(c *gin.Context) {
 	}
 
 	// Create a new WebSocket client
-	client := events.NewWebSocketClient(conn)
+	client := events.NewWebSocketClient(conn, h.logger)
+	if channel != nil {
+		client.SetChannel(*channel)
+	}
+	if since != nil {
+		client.SetReplaySince(*since)
+	}
 
-	// Register client with event service
-	h.eventService.RegisterClient(client)
+	// Register client with event service, resuming a previous session if requested
+	h.eventService.RegisterClient(client, c.Query("resume_token"))
 
 	// Start reading and writing goroutines
 	client.StartReading(h.eventService)
@@ -44,6 +105,7 @@ func (h *Handler) SubscribeToContractEvents(c *gin.Context) {
 	var req struct {
 		ContractAddress string   `json:"contractAddress" binding:"required"`
 		EventSignatures []string `json:"eventSignatures"`
+		ABI             string   `json:"abi"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -53,9 +115,38 @@ func (h *Handler) SubscribeToContractEvents(c *gin.Context) {
 		return
 	}
 
-	err := h.eventService.SubscribeToContract(req.ContractAddress, req.EventSignatures)
+	contract, err := parseAddress(c, req.ContractAddress)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !h.eventService.ContractAllowed(contract.Hex()) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": events.ErrContractNotAllowed.Error(),
+		})
+		return
+	}
+
+	// Register the ABI first so events are delivered by name
+	if req.ABI != "" {
+		if err := h.eventService.RegisterContractABI(contract.Hex(), req.ABI); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	err = h.eventService.SubscribeToContract(contract.Hex(), req.EventSignatures)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, events.ErrInvalidEventSignature) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error": err.Error(),
 		})
 		return
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/gin-gonic/gin"
+)
+
+// Gas used ratios from which the network is reported as moderately and highly
+// congested. Blocks are half full at their target.
+const (
+	mediumCongestion = 0.5
+	highCongestion   = 0.8
+)
+
+const (
+	defaultFeeHistoryBlocks  = 10
+	maxFeeHistoryBlocks      = 1024
+	maxFeeHistoryPercentiles = 100
+)
+
+// GetFeeHistory handles the fee history endpoint used by fee selection UIs
+func (h *Handler) GetFeeHistory(c *gin.Context) {
+	blocks := uint64(defaultFeeHistoryBlocks)
+	if s := c.Query("blocks"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil || n == 0 || n > maxFeeHistoryBlocks {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("blocks must be between 1 and %d", maxFeeHistoryBlocks),
+			})
+			return
+		}
+		blocks = n
+	}
+
+	percentiles, err := parsePercentiles(c.Query("percentiles"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	history, err := h.ethClient.FeeHistory(c.Request.Context(), blocks, percentiles)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetGasPrice handles the gas price endpoint, suggesting the fees of a
+// transaction to be included in the next blocks
+func (h *Handler) GetGasPrice(c *gin.Context) {
+	suggestion, err := h.ethClient.SuggestFees(c.Request.Context())
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestion)
+}
+
+// GetCongestion handles the network congestion endpoint, reporting how full
+// recent blocks were as a ratio and a low, medium or high level
+func (h *Handler) GetCongestion(c *gin.Context) {
+	ratio, err := h.ethClient.NetworkCongestion(c.Request.Context())
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	level := "low"
+	switch {
+	case ratio >= highCongestion:
+		level = "high"
+	case ratio >= mediumCongestion:
+		level = "medium"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"gasUsedRatio": ratio,
+		"level":        level,
+	})
+}
+
+// parsePercentiles parses a comma-separated list of ascending percentiles between 0 and 100
+func parsePercentiles(s string) ([]float64, error) {
+	values := splitList(s)
+	if len(values) > maxFeeHistoryPercentiles {
+		return nil, fmt.Errorf("at most %d percentiles are allowed", maxFeeHistoryPercentiles)
+	}
+
+	percentiles := make([]float64, 0, len(values))
+	for _, v := range values {
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q: must be between 0 and 100", v)
+		}
+		if n := len(percentiles); n > 0 && p <= percentiles[n-1] {
+			return nil, fmt.Errorf("percentiles must be in ascending order")
+		}
+		percentiles = append(percentiles, p)
+	}
+	return percentiles, nil
+}
+
+// GetConfirmationEstimate handles estimating how long a transaction paying
+// the gasPrice query parameter, in wei unless a unit is given, waits to be
+// included at recent fee levels
+func (h *Handler) GetConfirmationEstimate(c *gin.Context) {
+	if c.Query("gasPrice") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "gasPrice is required",
+		})
+		return
+	}
+	gasPrice, err := ethereum.ParseAmount(c.Query("gasPrice"), c.Query("unit"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if gasPrice.Sign() <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "gasPrice must be positive",
+		})
+		return
+	}
+
+	estimate, err := h.ethClient.EstimateConfirmationTime(c.Request.Context(), gasPrice)
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrGasPriceBelowBaseFee) || errors.Is(err, ethereum.ErrConfirmationUnlikely) {
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"gasPrice":         gasPrice.String(),
+		"estimatedSeconds": int64(estimate.Round(time.Second) / time.Second),
+	})
+}
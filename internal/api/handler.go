@@ -2,12 +2,18 @@ package api
 
 import (
 	"context"
-	"math/big"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"strconv"
 
+	"github.com/em/go-web3/internal/config"
 	"github.com/em/go-web3/internal/ethereum"
 	"github.com/em/go-web3/internal/events"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,13 +21,19 @@ import (
 type Handler struct {
 	ethClient    *ethereum.Client
 	eventService *events.Service
+	config       *config.ServerConfig
+	idempotency  *idempotencyStore
+	logger       *slog.Logger
 }
 
 // NewHandler creates a new API handler
-func NewHandler(ethClient *ethereum.Client, eventService *events.Service) *Handler {
+func NewHandler(ethClient *ethereum.Client, eventService *events.Service, cfg *config.ServerConfig, logger *slog.Logger) *Handler {
 	return &Handler{
 		ethClient:    ethClient,
 		eventService: eventService,
+		config:       cfg,
+		idempotency:  newIdempotencyStore(cfg.IdempotencyTTL),
+		logger:       logger,
 	}
 }
 
@@ -33,19 +45,47 @@ func (h *Handler) SetupRoutes(router *gin.Engine) {
 		eth := v1.Group("/eth")
 		{
 			eth.GET("/balance/:address", h.GetBalance)
+			eth.GET("/address/:address/pending", h.GetPendingTransactions)
+			eth.GET("/address/:address/tokens", h.GetTokenHoldings)
+			eth.GET("/token/:token", h.GetTokenInfo)
+			eth.GET("/proxy/:address", h.GetProxyImplementation)
+			eth.GET("/ens/:name", h.ResolveENSName)
+			eth.GET("/fee-history", h.GetFeeHistory)
+			eth.GET("/gas-price", h.GetGasPrice)
+			eth.GET("/congestion", h.GetCongestion)
+			eth.GET("/confirmation-estimate", h.GetConfirmationEstimate)
 			eth.POST("/transfer", h.SendTransaction)
+			eth.POST("/transfer/batch", h.SendBatchTransactions)
 			eth.GET("/tx/:hash", h.GetTransaction)
 			eth.GET("/tx/:hash/receipt", h.GetTransactionReceipt)
+			eth.POST("/tx/:hash/events", h.DecodeTransactionEvents)
 			eth.GET("/block/latest", h.GetLatestBlock)
+			eth.GET("/block/finalized", h.GetFinalizedBlock)
+			eth.GET("/block/safe", h.GetSafeBlock)
+			eth.GET("/block/by-time", h.GetBlockByTime)
 			eth.GET("/block/:number", h.GetBlockByNumber)
+			eth.GET("/block/:number/tx/:index", h.GetTransactionInBlock)
+			eth.POST("/contract/execute", h.ExecuteContract)
+			eth.POST("/contract/call", h.CallContract)
+			eth.POST("/verify-bytecode", h.VerifyBytecode)
+			eth.POST("/rpc", h.ForwardRPC)
+			eth.POST("/simulate-bundle", h.SimulateBundle)
+			eth.POST("/decode-input", h.DecodeInput)
+			eth.POST("/verify-signature", h.VerifySignature)
+			eth.POST("/create2-address", h.ComputeCreate2Address)
 		}
 
 		// Events endpoints
 		events := v1.Group("/events")
 		{
 			events.GET("/ws", h.EventsHandler)
+			events.GET("/ws/blocks", h.BlocksChannelHandler)
+			events.GET("/ws/transactions", h.TransactionsChannelHandler)
+			events.GET("/ws/contracts/:address", h.ContractChannelHandler)
 			events.POST("/subscribe", h.SubscribeToContractEvents)
 			events.GET("/latest/:type", h.GetLatestEvents)
+			events.GET("/history", h.GetEventHistory)
+			events.GET("/stream", h.StreamEvents)
 		}
 
 		// Transaction monitoring endpoints
@@ -53,51 +93,180 @@ func (h *Handler) SetupRoutes(router *gin.Engine) {
 		{
 			txMonitor.POST("/address", h.WatchAddressHandler)
 			txMonitor.POST("/high-value", h.WatchHighValueTransactionsHandler)
+			txMonitor.POST("/contract-creations", h.WatchContractCreationsHandler)
+			txMonitor.GET("/filters", h.ListFiltersHandler)
+			txMonitor.DELETE("/filters/:id", h.RemoveFilterHandler)
+		}
+
+		// Admin endpoints, only served behind an API key
+		if h.config.AdminAPIKey == "" {
+			h.logger.Warn("Admin endpoints are disabled, set server.adminAPIKey to enable them")
+		} else {
+			admin := v1.Group("/admin", apiKeyMiddleware(h.config.AdminAPIKey))
+			{
+				admin.GET("/dead-letters", h.GetDeadLetters)
+				admin.GET("/status", h.GetStatus)
+				admin.POST("/events/pause", h.PauseEvents)
+				admin.POST("/events/resume", h.ResumeEvents)
+			}
 		}
 
 		// Health check
 		v1.GET("/health", h.HealthCheck)
+
+		// Metrics
+		v1.GET("/metrics", gin.WrapH(expvar.Handler()))
 	}
 }
 
-// HealthCheck handles the health check endpoint
+// HealthCheck handles the health check endpoint. The service reports itself
+// degraded while the RPC circuit breaker is open or the provider is reconnecting.
 func (h *Handler) HealthCheck(c *gin.Context) {
+	breaker := h.ethClient.BreakerState()
+	connection := h.ethClient.ConnectionState()
+	status := "ok"
+	if breaker != ethereum.CircuitClosed || connection != ethereum.ConnectionConnected {
+		status = "degraded"
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
+		"status":         status,
+		"circuitBreaker": breaker,
+		"connection":     connection,
 	})
 }
 
+// rpcErrorStatus returns the HTTP status for an Ethereum client error,
+// failing fast with 503 while the node is considered unavailable or
+// the RPC concurrency limit stayed saturated
+func rpcErrorStatus(err error) int {
+	if errors.Is(err, ethereum.ErrCircuitOpen) || errors.Is(err, ethereum.ErrRPCSaturated) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
 // GetBalance handles the get balance endpoint
 func (h *Handler) GetBalance(c *gin.Context) {
-	address := c.Param("address")
-	if address == "" {
+	address, err := parseAddress(c, c.Param("address"))
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "address is required",
+			"error": err.Error(),
 		})
 		return
 	}
 
-	balance, err := h.ethClient.GetBalance(context.Background(), address)
+	if s := c.Query("confirmations"); s != "" {
+		h.getConfirmedBalance(c, address, s)
+		return
+	}
+
+	tag := c.DefaultQuery("block", ethereum.BlockTagLatest)
+	balance, err := h.ethClient.GetBalanceAtTag(c.Request.Context(), address, tag)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(blockTagErrorStatus(err), gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"address": address,
-		"balance": balance.String(),
+		"address":    address.Hex(),
+		"block":      tag,
+		"balance":    ethereum.NewAmount(balance),
+		"balanceEth": ethereum.WeiToEther(balance),
 	})
 }
 
+// getConfirmedBalance responds with the balance of an address as of the block
+// with the given number of confirmations
+func (h *Handler) getConfirmedBalance(c *gin.Context, address common.Address, s string) {
+	confirmations, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid confirmations",
+		})
+		return
+	}
+	if c.Query("block") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "block and confirmations can't be combined",
+		})
+		return
+	}
+
+	balance, blockNumber, err := h.ethClient.GetConfirmedBalance(c.Request.Context(), address.Hex(), confirmations)
+	if err != nil {
+		status := rpcErrorStatus(err)
+		switch {
+		case errors.Is(err, ethereum.ErrTooManyConfirmations):
+			status = http.StatusBadRequest
+		case errors.Is(err, ethereum.ErrStateUnavailable):
+			status = http.StatusNotImplemented
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"address":       address.Hex(),
+		"block":         blockNumber,
+		"confirmations": confirmations,
+		"balance":       ethereum.NewAmount(balance),
+		"balanceEth":    ethereum.WeiToEther(balance),
+	})
+}
+
+// blockTagErrorStatus returns the HTTP status for an error of a block tag request
+func blockTagErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ethereum.ErrInvalidBlockTag):
+		return http.StatusBadRequest
+	case errors.Is(err, ethereum.ErrBlockTagUnsupported):
+		return http.StatusNotImplemented
+	}
+	return rpcErrorStatus(err)
+}
+
+// GetTokenInfo handles the ERC20 token metadata endpoint
+func (h *Handler) GetTokenInfo(c *gin.Context) {
+	token, err := parseAddress(c, c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	info, err := h.ethClient.GetTokenInfo(c.Request.Context(), token.Hex())
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrNoCode) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
 // TransactionRequest represents a transaction request
 type TransactionRequest struct {
-	To     string `json:"to" binding:"required"`
-	Amount string `json:"amount" binding:"required"`
+	To       string `json:"to" binding:"required"`
+	Amount   string `json:"amount" binding:"required"`
+	Simulate bool   `json:"simulate"` // Run the transaction with eth_call first and abort if it would revert
+	GasLimit uint64 `json:"gasLimit"` // Optional, estimated when omitted
+	Unit     string `json:"unit"`     // Unit of amount: wei (default), gwei or ether
+	Speed    string `json:"speed"`    // Fee tier: slow, standard (default) or fast
 }
 
-// SendTransaction handles the send transaction endpoint
+// SendTransaction handles the send transaction endpoint.
+// Requests carrying an Idempotency-Key header are sent at most once per key.
 func (h *Handler) SendTransaction(c *gin.Context) {
 	var req TransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -107,27 +276,333 @@ func (h *Handler) SendTransaction(c *gin.Context) {
 		return
 	}
 
-	amount, ok := new(big.Int).SetString(req.Amount, 10)
-	if !ok {
+	to, err := parseAddress(c, req.To)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "invalid amount format",
+			"error": err.Error(),
 		})
 		return
 	}
 
-	txHash, err := h.ethClient.SendTransaction(context.Background(), req.To, amount)
+	idempotencyKey := c.GetHeader("Idempotency-Key")
+	if idempotencyKey != "" {
+		txHash, done, err := h.idempotency.Begin(idempotencyKey, req)
+		if err != nil {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if done {
+			c.JSON(http.StatusOK, gin.H{
+				"txHash":   txHash,
+				"replayed": true,
+			})
+			return
+		}
+
+		// Release the key if nothing gets sent, so the request can be retried.
+		// Keys are completed before returning when the transaction may be sent.
+		defer h.idempotency.Abort(idempotencyKey)
+	}
+
+	amount, err := ethereum.ParseAmount(req.Amount, req.Unit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if amount.Sign() < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "amount must not be negative",
+		})
+		return
+	}
+
+	speed, err := ethereum.ParseGasStrategy(req.Speed)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if req.Simulate {
+		if err := h.ethClient.SimulateTransaction(c.Request.Context(), to.Hex(), amount, nil); err != nil {
+			var revertErr *ethereum.RevertError
+			if errors.As(err, &revertErr) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{
+					"error":  revertErr.Error(),
+					"reason": revertErr.Reason,
+				})
+				return
+			}
+			c.JSON(rpcErrorStatus(err), gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	sent, err := h.ethClient.SubmitTransaction(c.Request.Context(), ethereum.TxOptions{
+		To:       &to,
+		Value:    amount,
+		GasLimit: req.GasLimit,
+		Speed:    speed,
+	})
+	if errors.Is(err, ethereum.ErrGasLimitTooLow) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	var broadcastErr *ethereum.BroadcastError
+	if errors.As(err, &broadcastErr) {
+		// The node may have the transaction: a retry with the same key must
+		// get its hash rather than send the transfer again
+		txHash := broadcastErr.Tx.Hash().Hex()
+		if idempotencyKey != "" {
+			h.idempotency.Complete(idempotencyKey, txHash)
+		}
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":  err.Error(),
+			"txHash": txHash,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if idempotencyKey != "" {
+		h.idempotency.Complete(idempotencyKey, sent.Hash)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"txHash":   sent.Hash,
+		"gasLimit": sent.GasLimit,
+	})
+}
+
+// maxBatchTransfers is the largest number of transfers accepted in one batch
+const maxBatchTransfers = 100
+
+// BatchTransferItem is a single transfer of a batch
+type BatchTransferItem struct {
+	To     string `json:"to" binding:"required"`
+	Amount string `json:"amount" binding:"required"`
+	Unit   string `json:"unit"` // Unit of amount: wei (default), gwei or ether
+}
+
+// BatchTransferRequest represents a request to send several transfers
+type BatchTransferRequest struct {
+	Transfers []BatchTransferItem `json:"transfers" binding:"required,min=1,dive"`
+}
+
+// SendBatchTransactions handles the batch transfer endpoint. Transfers are
+// sent in order with consecutive nonces; a failed transfer doesn't stop the
+// batch, and each transfer's outcome is reported.
+func (h *Handler) SendBatchTransactions(c *gin.Context) {
+	var req BatchTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if len(req.Transfers) > maxBatchTransfers {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("at most %d transfers are allowed per batch", maxBatchTransfers),
+		})
+		return
+	}
+
+	results := make([]gin.H, len(req.Transfers))
+	failed := 0
+	for i, transfer := range req.Transfers {
+		result := gin.H{
+			"index": i,
+			"to":    transfer.To,
+		}
+		sent, err := h.sendBatchTransfer(c, transfer)
+		if err != nil {
+			result["error"] = err.Error()
+			failed++
+		} else {
+			result["txHash"] = sent.Hash
+			result["gasLimit"] = sent.GasLimit
+		}
+		results[i] = result
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
+
+// sendBatchTransfer validates and sends one transfer of a batch
+func (h *Handler) sendBatchTransfer(c *gin.Context, transfer BatchTransferItem) (*ethereum.SentTransaction, error) {
+	to, err := parseAddress(c, transfer.To)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := ethereum.ParseAmount(transfer.Amount, transfer.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("amount must not be negative")
+	}
+
+	return h.ethClient.SubmitTransaction(c.Request.Context(), ethereum.TxOptions{
+		To:    &to,
+		Value: amount,
+	})
+}
+
+// GetPendingTransactions handles listing an address's transactions in the node's pool
+func (h *Handler) GetPendingTransactions(c *gin.Context) {
+	address, err := parseAddress(c, c.Param("address"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	pending, err := h.ethClient.GetPendingTransactions(c.Request.Context(), address.Hex())
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrTxPoolUnsupported) {
+			status = http.StatusNotImplemented
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"address": address.Hex(),
+		"pending": pooledTransactions(pending.Pending),
+		"queued":  pooledTransactions(pending.Queued),
+	})
+}
+
+// pooledTransactions formats transactions from the node's pool for a response
+func pooledTransactions(txs []*types.Transaction) []gin.H {
+	out := make([]gin.H, len(txs))
+	for i, tx := range txs {
+		item := gin.H{
+			"hash":     tx.Hash().Hex(),
+			"nonce":    tx.Nonce(),
+			"value":    ethereum.NewAmount(tx.Value()),
+			"gas":      tx.Gas(),
+			"gasPrice": tx.GasPrice().String(),
+		}
+		if tx.To() != nil {
+			item["to"] = tx.To().Hex()
+		}
+		out[i] = item
+	}
+	return out
+}
+
+// GetTokenHoldings handles listing the ERC20 tokens held by an address.
+// Tokens are discovered from Transfer logs since fromBlock; 0, the default,
+// means the oldest block of the largest allowed range.
+func (h *Handler) GetTokenHoldings(c *gin.Context) {
+	address, err := parseAddress(c, c.Param("address"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var fromBlock uint64
+	if s := c.Query("fromBlock"); s != "" {
+		fromBlock, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid fromBlock",
+			})
+			return
+		}
+	}
+
+	// Bound the scan; it is also aborted if the client disconnects
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.ScanTimeout)
+	defer cancel()
+
+	holdings, err := h.ethClient.GetTokenHoldings(ctx, address.Hex(), fromBlock)
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrScanRangeTooLarge) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
+	tokens := make([]gin.H, len(holdings))
+	for i, holding := range holdings {
+		tokens[i] = gin.H{
+			"token":   holding.Token.Hex(),
+			"balance": ethereum.NewAmount(holding.Balance),
+		}
+	}
 	c.JSON(http.StatusOK, gin.H{
-		"txHash": txHash,
+		"address":   address.Hex(),
+		"fromBlock": fromBlock,
+		"tokens":    tokens,
 	})
 }
 
+// ResolveENSName handles resolving an ENS name to an address or, with
+// reverse=true, an address to its primary name. Failed resolutions report the
+// resolver reached and the step that failed.
+func (h *Handler) ResolveENSName(c *gin.Context) {
+	var resolution *ethereum.ENSResolution
+	var err error
+	if c.Query("reverse") == "true" {
+		address, parseErr := parseAddress(c, c.Param("name"))
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": parseErr.Error(),
+			})
+			return
+		}
+		resolution, err = h.ethClient.LookupAddressDetails(c.Request.Context(), address)
+	} else {
+		resolution, err = h.ethClient.ResolveNameDetails(c.Request.Context(), c.Param("name"))
+	}
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	switch resolution.FailedStep {
+	case "":
+		c.JSON(http.StatusOK, resolution)
+	case ethereum.ENSStepOffchainLookup:
+		c.JSON(http.StatusBadGateway, resolution)
+	default:
+		c.JSON(http.StatusNotFound, resolution)
+	}
+}
+
 // GetTransaction handles the get transaction endpoint
 func (h *Handler) GetTransaction(c *gin.Context) {
 	hash := c.Param("hash")
@@ -138,17 +613,117 @@ func (h *Handler) GetTransaction(c *gin.Context) {
 		return
 	}
 
-	tx, isPending, err := h.ethClient.GetTransactionByHash(context.Background(), hash)
+	tx, isPending, err := h.ethClient.GetTransactionByHash(c.Request.Context(), hash)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.transactionResponse(tx)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
+	response["hash"] = hash
+	response["isPending"] = isPending
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetBlockByTime handles the find block by timestamp endpoint, returning the
+// block closest to the timestamp and how far its own timestamp is from it
+func (h *Handler) GetBlockByTime(c *gin.Context) {
+	target, err := strconv.ParseInt(c.Query("timestamp"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "timestamp must be a Unix time in seconds",
+		})
+		return
+	}
+
+	number, err := h.ethClient.BlockByTimestamp(c.Request.Context(), target)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 
-	// For simplicity, we won't try to get the sender address which requires more complex handling
-	// In a production app, you would use a TransactionSigner to recover the sender
+	timestamp, err := h.ethClient.GetBlockTime(c.Request.Context(), number)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"blockNumber":     number,
+		"timestamp":       timestamp,
+		"targetTimestamp": target,
+		"deviation":       int64(timestamp) - target, // Seconds the block is after (or before) the target
+	})
+}
+
+// GetTransactionInBlock handles the get transaction by block number and index endpoint
+func (h *Handler) GetTransactionInBlock(c *gin.Context) {
+	number, err := strconv.ParseUint(c.Param("number"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid block number",
+		})
+		return
+	}
+
+	index, err := strconv.ParseUint(c.Param("index"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid transaction index",
+		})
+		return
+	}
+
+	block, err := h.ethClient.GetBlockByNumber(c.Request.Context(), number)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	tx, err := h.ethClient.GetTransactionInBlock(c.Request.Context(), block.Hash(), uint(index))
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrTxIndexOutOfRange) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.transactionResponse(tx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	response["hash"] = tx.Hash().Hex()
+	response["blockNumber"] = number
+	response["blockHash"] = block.Hash().Hex()
+	response["index"] = index
 
+	c.JSON(http.StatusOK, response)
+}
+
+// transactionResponse describes a transaction, including its recovered sender
+func (h *Handler) transactionResponse(tx *types.Transaction) (gin.H, error) {
 	// Check if To address is nil (contract creation)
 	var to string
 	if tx.To() != nil {
@@ -157,15 +732,29 @@ func (h *Handler) GetTransaction(c *gin.Context) {
 		to = "contract creation"
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"hash":      hash,
-		"isPending": isPending,
-		"to":        to,
-		"value":     tx.Value().String(),
-		"gasPrice":  tx.GasPrice().String(),
-		"gas":       tx.Gas(),
-		"nonce":     tx.Nonce(),
-	})
+	from, err := ethereum.RecoverSender(tx, h.ethClient.ConfiguredChainID())
+	if err != nil {
+		return nil, err
+	}
+
+	response := gin.H{
+		"from":     from.Hex(),
+		"to":       to,
+		"value":    ethereum.NewAmount(tx.Value()),
+		"gasPrice": tx.GasPrice().String(),
+		"gas":      tx.Gas(),
+		"nonce":    tx.Nonce(),
+		"type":     tx.Type(),
+	}
+	// Legacy transactions have no access list, typed ones always carry one, possibly empty
+	if tx.Type() != types.LegacyTxType {
+		accessList := tx.AccessList()
+		if accessList == nil {
+			accessList = types.AccessList{}
+		}
+		response["accessList"] = accessList
+	}
+	return response, nil
 }
 
 // GetTransactionReceipt handles the get transaction receipt endpoint
@@ -178,49 +767,207 @@ func (h *Handler) GetTransactionReceipt(c *gin.Context) {
 		return
 	}
 
-	receipt, err := h.ethClient.GetTransactionReceipt(context.Background(), hash)
+	var required uint64
+	if s := c.Query("confirmations"); s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid confirmations",
+			})
+			return
+		}
+		required = n
+	}
+
+	var receipt *types.Receipt
+	var err error
+	if c.Query("wait") == "true" {
+		receipt, err = h.ethClient.WaitForReceipt(c.Request.Context(), hash)
+	} else {
+		receipt, err = h.ethClient.GetTransactionReceipt(c.Request.Context(), hash)
+	}
+	if errors.Is(err, ethereum.ErrReceiptTimeout) {
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Withhold the receipt until the transaction is final enough for the caller
+	var confirmations uint64
+	if required > 0 {
+		confirmations, err = h.ethClient.GetConfirmations(c.Request.Context(), hash)
+		if err != nil {
+			c.JSON(rpcErrorStatus(err), gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if confirmations < required {
+			c.JSON(http.StatusAccepted, gin.H{
+				"txHash":                hash,
+				"confirmations":         confirmations,
+				"requiredConfirmations": required,
+			})
+			return
+		}
+	}
+
+	response := gin.H{
+		"txHash":      hash,
+		"blockHash":   receipt.BlockHash.Hex(),
+		"blockNumber": receipt.BlockNumber.String(),
+		"gasUsed":     receipt.GasUsed,
+		"status":      receipt.Status,
+	}
+	// Only contract creations have a contract address
+	if receipt.ContractAddress != (common.Address{}) {
+		response["contractAddress"] = receipt.ContractAddress.Hex()
+	}
+	if receipt.EffectiveGasPrice != nil {
+		response["effectiveGasPrice"] = receipt.EffectiveGasPrice.String()
+	}
+	if required > 0 {
+		response["confirmations"] = confirmations
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DecodeTransactionEventsRequest holds the ABI used to decode a transaction's logs
+type DecodeTransactionEventsRequest struct {
+	ABI string `json:"abi" binding:"required"`
+}
+
+// DecodeTransactionEvents handles decoding the events emitted by a transaction
+func (h *Handler) DecodeTransactionEvents(c *gin.Context) {
+	var req DecodeTransactionEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	hash := c.Param("hash")
+	logs, err := h.ethClient.DecodeReceiptLogs(c.Request.Context(), hash, req.ABI)
+	if err != nil {
+		status := rpcErrorStatus(err)
+		if errors.Is(err, ethereum.ErrInvalidABI) {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"txHash":          hash,
-		"blockHash":       receipt.BlockHash.Hex(),
-		"blockNumber":     receipt.BlockNumber.String(),
-		"gasUsed":         receipt.GasUsed,
-		"status":          receipt.Status,
-		"contractAddress": receipt.ContractAddress.Hex(),
+		"txHash": hash,
+		"events": logs,
 	})
 }
 
-// GetLatestBlock handles the get latest block endpoint
+// GetLatestBlock handles the get latest block endpoint. With wait=true it
+// long-polls until the listener sees a block after since.
 func (h *Handler) GetLatestBlock(c *gin.Context) {
-	blockNumber, err := h.ethClient.GetLatestBlockNumber(context.Background())
+	wait := c.Query("wait") == "true"
+	var since uint64
+	if s := c.Query("since"); wait && s != "" {
+		n, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid since",
+			})
+			return
+		}
+		since = n
+	}
+
+	blockNumber, err := h.ethClient.GetLatestBlockNumber(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(rpcErrorStatus(err), gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	block, err := h.ethClient.GetBlockByNumber(context.Background(), blockNumber)
+	if wait && c.Query("since") == "" {
+		since = blockNumber
+	}
+	if wait && blockNumber <= since {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.LongPollTimeout)
+		number, err := h.eventService.WaitForBlock(ctx, since)
+		cancel()
+		if c.Request.Context().Err() != nil {
+			// The client went away
+			return
+		}
+		if err == nil {
+			blockNumber = number
+		} else if number, err := h.ethClient.GetLatestBlockNumber(c.Request.Context()); err == nil {
+			// Timed out: return the current latest block, which the listener may not have emitted
+			blockNumber = number
+		}
+	}
+
+	block, err := h.ethClient.GetBlockByNumber(c.Request.Context(), blockNumber)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(rpcErrorStatus(err), gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, blockResponse(block))
+}
+
+// blockResponse formats the block info returned by the block endpoints.
+// baseFeePerGas is left out for blocks from before London.
+func blockResponse(block *types.Block) gin.H {
+	response := gin.H{
 		"number":     block.Number().String(),
 		"hash":       block.Hash().Hex(),
 		"parentHash": block.ParentHash().Hex(),
 		"timestamp":  block.Time(),
 		"txCount":    len(block.Transactions()),
-	})
+		"uncleCount": len(block.Uncles()),
+		"gasUsed":    block.GasUsed(),
+		"gasLimit":   block.GasLimit(),
+	}
+	if baseFee := block.BaseFee(); baseFee != nil {
+		response["baseFeePerGas"] = baseFee.String()
+	}
+	return response
+}
+
+// GetFinalizedBlock handles the get finalized block endpoint
+func (h *Handler) GetFinalizedBlock(c *gin.Context) {
+	h.getBlockByTag(c, ethereum.BlockTagFinalized)
+}
+
+// GetSafeBlock handles the get safe block endpoint
+func (h *Handler) GetSafeBlock(c *gin.Context) {
+	h.getBlockByTag(c, ethereum.BlockTagSafe)
+}
+
+// getBlockByTag responds with the block info of a block tag
+func (h *Handler) getBlockByTag(c *gin.Context, tag string) {
+	block, err := h.ethClient.GetBlockByTag(c.Request.Context(), tag)
+	if err != nil {
+		c.JSON(blockTagErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, blockResponse(block))
 }
 
 // GetBlockByNumber handles the get block by number endpoint
@@ -241,19 +988,13 @@ func (h *Handler) GetBlockByNumber(c *gin.Context) {
 		return
 	}
 
-	block, err := h.ethClient.GetBlockByNumber(context.Background(), number)
+	block, err := h.ethClient.GetBlockByNumber(c.Request.Context(), number)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(rpcErrorStatus(err), gin.H{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"number":     block.Number().String(),
-		"hash":       block.Hash().Hex(),
-		"parentHash": block.ParentHash().Hex(),
-		"timestamp":  block.Time(),
-		"txCount":    len(block.Transactions()),
-	})
+	c.JSON(http.StatusOK, blockResponse(block))
 }
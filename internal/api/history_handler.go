@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+// historyCursor is the decoded form of the opaque pagination cursor
+type historyCursor struct {
+	Block uint64 `json:"b"`
+	Index uint   `json:"i"`
+}
+
+// encodeCursor encodes a log position as an opaque, URL-safe cursor
+func encodeCursor(pos *ethereum.LogPosition) string {
+	if pos == nil {
+		return ""
+	}
+	raw, _ := json.Marshal(historyCursor{Block: pos.BlockNumber, Index: pos.Index})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor decodes a cursor returned by encodeCursor
+func decodeCursor(cursor string) (*ethereum.LogPosition, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var decoded historyCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &ethereum.LogPosition{BlockNumber: decoded.Block, Index: decoded.Index}, nil
+}
+
+// GetEventHistory handles the historical logs endpoint. Results are paged in
+// chain order; pass the returned nextCursor with the same query for the next page.
+func (h *Handler) GetEventHistory(c *gin.Context) {
+	query, err := parseLogQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	limit, err := parseLimit(c, defaultHistoryLimit, maxHistoryLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var after *ethereum.LogPosition
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err = decodeCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	// Default to the chain head when no end block is given
+	if c.Query("toBlock") == "" {
+		head, err := h.ethClient.GetLatestBlockNumber(c.Request.Context())
+		if err != nil {
+			c.JSON(rpcErrorStatus(err), gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		query.ToBlock = head
+	}
+	if query.FromBlock > query.ToBlock {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "fromBlock must not be after toBlock",
+		})
+		return
+	}
+
+	// Bound the scan; it is also aborted if the client disconnects
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.config.ScanTimeout)
+	defer cancel()
+
+	page, err := h.ethClient.GetLogsPage(ctx, query, after, limit)
+	if err != nil {
+		c.JSON(rpcErrorStatus(err), gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logs := page.Logs
+	if logs == nil {
+		logs = []types.Log{}
+	}
+	c.JSON(http.StatusOK, listPage{
+		Items:      logs,
+		NextCursor: encodeCursor(page.Next),
+		Truncated:  page.Truncated,
+	})
+}
+
+// parseLogQuery reads the contract, topic and block range query parameters.
+// contract may be repeated; topic0..topic3 accept comma-separated alternatives.
+func parseLogQuery(c *gin.Context) (ethereum.LogQuery, error) {
+	var query ethereum.LogQuery
+
+	for _, addr := range c.QueryArray("contract") {
+		address, err := parseAddress(c, addr)
+		if err != nil {
+			return query, err
+		}
+		query.Addresses = append(query.Addresses, address)
+	}
+
+	for i := 0; i < 4; i++ {
+		param := c.Query(fmt.Sprintf("topic%d", i))
+		var position []common.Hash
+		if param != "" {
+			for _, topic := range strings.Split(param, ",") {
+				b, err := parseTopic(topic)
+				if err != nil {
+					return query, fmt.Errorf("invalid topic%d %q", i, topic)
+				}
+				position = append(position, b)
+			}
+		}
+		query.Topics = append(query.Topics, position)
+	}
+	// Drop trailing wildcard positions
+	for len(query.Topics) > 0 && len(query.Topics[len(query.Topics)-1]) == 0 {
+		query.Topics = query.Topics[:len(query.Topics)-1]
+	}
+
+	var err error
+	if s := c.Query("fromBlock"); s != "" {
+		if query.FromBlock, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return query, fmt.Errorf("invalid fromBlock")
+		}
+	}
+	if s := c.Query("toBlock"); s != "" {
+		if query.ToBlock, err = strconv.ParseUint(s, 10, 64); err != nil {
+			return query, fmt.Errorf("invalid toBlock")
+		}
+	}
+
+	return query, nil
+}
+
+// parseTopic parses a 0x-prefixed 32-byte hex topic
+func parseTopic(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(strings.TrimSpace(s))
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid topic")
+	}
+	return common.BytesToHash(b), nil
+}
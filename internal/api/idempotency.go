@@ -0,0 +1,117 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// errIdempotencyMismatch is returned when a key is reused with a different request body
+	errIdempotencyMismatch = errors.New("idempotency key was already used with a different request")
+	// errIdempotencyInProgress is returned when a request with the same key is still being processed
+	errIdempotencyInProgress = errors.New("a request with this idempotency key is already in progress")
+)
+
+// idempotencyEntry records the outcome of a request made with an idempotency key
+type idempotencyEntry struct {
+	fingerprint string
+	txHash      string // Empty while the request is in progress
+	expiresAt   time.Time
+}
+
+// idempotencyStore maps idempotency keys to transaction hashes for a TTL
+type idempotencyStore struct {
+	ttl       time.Duration
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+// newIdempotencyStore creates a new idempotency store
+func newIdempotencyStore(ttl time.Duration) *idempotencyStore {
+	return &idempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// Begin reserves a key for a request. If the key was already used with the same
+// request, the previous transaction hash is returned with done set to true.
+func (s *idempotencyStore) Begin(key string, request interface{}) (txHash string, done bool, err error) {
+	fingerprint, err := requestFingerprint(request)
+	if err != nil {
+		return "", false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		if entry.fingerprint != fingerprint {
+			return "", false, errIdempotencyMismatch
+		}
+		if entry.txHash == "" {
+			return "", false, errIdempotencyInProgress
+		}
+		return entry.txHash, true, nil
+	}
+
+	s.entries[key] = &idempotencyEntry{
+		fingerprint: fingerprint,
+		expiresAt:   now.Add(s.ttl),
+	}
+	return "", false, nil
+}
+
+// Complete stores the transaction hash for a reserved key
+func (s *idempotencyStore) Complete(key, txHash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.txHash = txHash
+		entry.expiresAt = time.Now().Add(s.ttl)
+	}
+}
+
+// Abort releases a reserved key so the request can be retried.
+// Keys that were already completed are kept.
+func (s *idempotencyStore) Abort(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.txHash == "" {
+		delete(s.entries, key)
+	}
+}
+
+// sweep removes expired entries, at most once a minute
+func (s *idempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// requestFingerprint hashes a request so reused keys can be compared
+func requestFingerprint(request interface{}) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes of lists held in memory
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// listPage is the envelope of list responses. Total is set when the size of
+// the whole list is known, and NextCursor is left out on the last page.
+type listPage struct {
+	Items      interface{} `json:"items"`
+	Total      *int        `json:"total,omitempty"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+	Truncated  bool        `json:"truncated,omitempty"` // The scan behind the page stopped early
+}
+
+// pageParams selects a page of a list held in memory
+type pageParams struct {
+	Limit  int
+	Offset int
+}
+
+// parseLimit reads the limit query parameter, between 1 and maxLimit
+func parseLimit(c *gin.Context, defaultLimit, maxLimit int) (int, error) {
+	s := c.Query("limit")
+	if s == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(s)
+	if err != nil || limit <= 0 || limit > maxLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", maxLimit)
+	}
+	return limit, nil
+}
+
+// parsePageParams reads the limit and offset query parameters. The cursor
+// returned with a page can be passed instead of offset.
+func parsePageParams(c *gin.Context, defaultLimit, maxLimit int) (pageParams, error) {
+	limit, err := parseLimit(c, defaultLimit, maxLimit)
+	if err != nil {
+		return pageParams{}, err
+	}
+
+	offset := c.Query("offset")
+	if cursor := c.Query("cursor"); cursor != "" {
+		offset = cursor
+	}
+	params := pageParams{Limit: limit}
+	if offset != "" {
+		params.Offset, err = strconv.Atoi(offset)
+		if err != nil || params.Offset < 0 {
+			return pageParams{}, fmt.Errorf("invalid offset or cursor")
+		}
+	}
+	return params, nil
+}
+
+// paginate returns the page of items selected by params
+func paginate[T any](items []T, params pageParams) listPage {
+	total := len(items)
+	start := min(params.Offset, total)
+	end := min(start+params.Limit, total)
+
+	page := listPage{
+		Items: append([]T{}, items[start:end]...),
+		Total: &total,
+	}
+	if end < total {
+		page.NextCursor = strconv.Itoa(end)
+	}
+	return page
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/gin-gonic/gin"
+)
+
+// RPCRequest is a JSON-RPC request forwarded to the node
+type RPCRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method" binding:"required"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// ForwardRPC handles the JSON-RPC passthrough endpoint. Only methods on the
+// configured allowlist are forwarded; the node's answer is returned as a
+// JSON-RPC response.
+func (h *Handler) ForwardRPC(c *gin.Context) {
+	var req RPCRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if !slices.Contains(h.config.RPCAllowlist, req.Method) {
+		h.logger.Warn("Rejected JSON-RPC passthrough call", "method", req.Method, "clientIP", c.ClientIP())
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("method %s is not allowed", req.Method),
+		})
+		return
+	}
+
+	id := req.ID
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	result, err := h.ethClient.RawCall(c.Request.Context(), req.Method, req.Params)
+	if err != nil {
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) {
+			c.JSON(rpcErrorStatus(err), gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		// Errors from the node are passed on in JSON-RPC form
+		rpcError := gin.H{
+			"code":    rpcErr.ErrorCode(),
+			"message": rpcErr.Error(),
+		}
+		var dataErr rpc.DataError
+		if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+			rpcError["data"] = dataErr.ErrorData()
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"jsonrpc": "2.0",
+			"id":      id,
+			"error":   rpcError,
+		})
+		return
+	}
+
+	if len(result) == 0 {
+		result = json.RawMessage("null")
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"result":  result,
+	})
+}
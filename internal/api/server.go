@@ -2,9 +2,13 @@ package api
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"time"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/em/go-web3/internal/config"
 	"github.com/gin-gonic/gin"
@@ -15,22 +19,32 @@ type Server struct {
 	router *gin.Engine
 	server *http.Server
 	config *config.ServerConfig
+	logger *slog.Logger
 }
 
 // NewServer creates a new server instance
-func NewServer(cfg *config.ServerConfig, handler *Handler) *Server {
+func NewServer(cfg *config.ServerConfig, handler *Handler, logger *slog.Logger) (*Server, error) {
 	router := gin.Default()
 
+	// Only trust X-Forwarded-For from known proxies, so c.ClientIP() can't be spoofed
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	// Add middleware
 	router.Use(gin.Recovery())
 	router.Use(gin.Logger())
+	router.Use(bodyLimitMiddleware(cfg.MaxBodyBytes))
 
 	// Serve static files
-	router.Static("/static", "./static")
-	router.StaticFile("/", "./static/index.html")
+	if cfg.StaticDir != "" {
+		router.Static("/static", cfg.StaticDir)
+		router.StaticFile("/", filepath.Join(cfg.StaticDir, "index.html"))
+	}
 
 	// Setup routes
 	handler.SetupRoutes(router)
+	router.NoRoute(notFoundHandler(cfg.StaticDir))
 
 	// Create HTTP server
 	addr := cfg.Host + ":" + cfg.Port
@@ -43,28 +57,53 @@ func NewServer(cfg *config.ServerConfig, handler *Handler) *Server {
 		router: router,
 		server: server,
 		config: cfg,
+		logger: logger,
+	}, nil
+}
+
+// notFoundHandler returns JSON 404s for unmatched API routes. Other GET requests
+// are served from the static directory, falling back to index.html so
+// client-side routes of a single-page app resolve.
+func notFoundHandler(staticDir string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		urlPath := c.Request.URL.Path
+		isRead := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+		if staticDir == "" || strings.HasPrefix(urlPath, "/api/") || !isRead {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "not found",
+			})
+			return
+		}
+
+		// Cleaning the rooted path keeps it inside the static directory
+		file := filepath.Join(staticDir, filepath.FromSlash(path.Clean("/"+urlPath)))
+		if info, err := os.Stat(file); err == nil && !info.IsDir() {
+			c.File(file)
+			return
+		}
+		c.File(filepath.Join(staticDir, "index.html"))
 	}
 }
 
 // Start starts the server
 func (s *Server) Start() error {
-	log.Printf("Starting server on %s:%s\n", s.config.Host, s.config.Port)
+	s.logger.Info("Starting server", "host", s.config.Host, "port", s.config.Port)
 	return s.server.ListenAndServe()
 }
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
-	log.Println("Shutting down server...")
+	s.logger.Info("Shutting down server")
 	return s.server.Shutdown(ctx)
 }
 
-// GracefulShutdown starts a goroutine to handle graceful shutdown
-func (s *Server) GracefulShutdown(quit <-chan struct{}) {
-	<-quit
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// GracefulShutdown shuts the server down, waiting for in-flight requests
+// for at most the configured shutdown timeout
+func (s *Server) GracefulShutdown(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.ShutdownTimeout)
 	defer cancel()
 
 	if err := s.Shutdown(ctx); err != nil {
-		log.Fatalf("Server shutdown error: %v", err)
+		s.logger.Error("Server shutdown error", "error", err)
 	}
 }
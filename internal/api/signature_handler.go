@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gin-gonic/gin"
+)
+
+// VerifySignatureRequest represents a request to verify a signed personal message
+type VerifySignatureRequest struct {
+	Message   string `json:"message" binding:"required"`   // Signed text, as passed to personal_sign
+	Signature string `json:"signature" binding:"required"` // 65-byte hex signature
+	Address   string `json:"address" binding:"required"`   // Claimed signer
+}
+
+// VerifySignature handles verifying that an EIP-191 personal message was
+// signed by the claimed address, the server side of wallet sign-in
+func (h *Handler) VerifySignature(c *gin.Context) {
+	var req VerifySignatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	address, err := parseAddress(c, req.Address)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	signature, err := hexutil.Decode(req.Signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "malformed signature: " + err.Error(),
+		})
+		return
+	}
+
+	signer, err := ethereum.RecoverPersonalSigner([]byte(req.Message), signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"valid":   signer == address,
+		"address": address.Hex(),
+		"signer":  signer.Hex(),
+	})
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/em/go-web3/internal/events"
+	"github.com/gin-gonic/gin"
+)
+
+// StreamEvents streams events as newline-delimited JSON over a long-lived
+// HTTP response. Filters are given as comma-separated eventTypes and contracts
+// query parameters and topic0..topic3 as in the history endpoint.
+func (h *Handler) StreamEvents(c *gin.Context) {
+	if h.eventService.RequiresAuth() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "event streams are unavailable when WebSocket authentication is required",
+		})
+		return
+	}
+
+	filters, err := parseStreamFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	stream := h.eventService.OpenStream(filters)
+	defer h.eventService.CloseStream(stream)
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // Disable proxy buffering
+	c.Status(http.StatusOK)
+
+	// c.Stream flushes after each event and stops when the client disconnects
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case message := <-stream.Events():
+			w.Write(message)
+			w.Write([]byte{'\n'})
+			return true
+		case <-stream.Done():
+			return false
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// parseStreamFilters builds event filters from the stream query parameters
+func parseStreamFilters(c *gin.Context) (events.EventFilters, error) {
+	var filters events.EventFilters
+
+	for _, eventType := range splitList(c.Query("eventTypes")) {
+		filters.EventTypes = append(filters.EventTypes, events.EventType(eventType))
+	}
+	filters.ContractAddress = splitList(c.Query("contracts"))
+
+	query, err := parseLogQuery(c)
+	if err != nil {
+		return filters, err
+	}
+	for _, position := range query.Topics {
+		var topics []string
+		for _, topic := range position {
+			topics = append(topics, topic.Hex())
+		}
+		filters.Topics = append(filters.Topics, topics)
+	}
+
+	return filters, nil
+}
+
+// splitList splits a comma-separated query parameter, ignoring empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
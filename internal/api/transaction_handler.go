@@ -1,24 +1,35 @@
 package api
 
 import (
-	"math/big"
+	"errors"
 	"net/http"
 
+	"github.com/em/go-web3/internal/ethereum"
 	"github.com/em/go-web3/internal/events"
-	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/gin-gonic/gin"
 )
 
-// WatchAddressRequest represents a request to watch a specific address
+// WatchAddressRequest represents a request to watch a specific address, or
+// calls of a contract method given by name with the contract's ABI
 type WatchAddressRequest struct {
-	Address string `json:"address" binding:"required"`
+	Address  string `json:"address"`
+	Contract string `json:"contract"` // Only transactions sent to the contract
+	ABI      string `json:"abi"`
+	Method   string `json:"method"` // Method name or signature in ABI, requires abi
+
+	OnlySuccessful bool `json:"onlySuccessful"` // Leave out reverted transactions, requires receipts
 }
 
 // WatchHighValueTransactionsRequest represents a request to watch for high-value transactions
 type WatchHighValueTransactionsRequest struct {
-	MinValue string `json:"minValue" binding:"required"` // In ETH as a string
+	MinValue       string `json:"minValue" binding:"required"` // In ETH as a string
+	OnlySuccessful bool   `json:"onlySuccessful"`              // Leave out reverted transactions, requires receipts
 }
 
+// errReceiptsDisabled rejects filters on the receipt status while receipts aren't fetched
+var errReceiptsDisabled = errors.New("onlySuccessful requires events.transactionReceipts to be enabled")
+
 // WatchAddressHandler handles adding an address to the watch list
 func (h *Handler) WatchAddressHandler(c *gin.Context) {
 	var req WatchAddressRequest
@@ -29,24 +40,111 @@ func (h *Handler) WatchAddressHandler(c *gin.Context) {
 		return
 	}
 
+	if req.OnlySuccessful && !h.eventService.ReceiptsEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errReceiptsDisabled.Error(),
+		})
+		return
+	}
+	if req.Contract != "" || req.Method != "" {
+		h.watchMethodCalls(c, req)
+		return
+	}
+	if req.Address == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "address or contract is required",
+		})
+		return
+	}
+
 	// Validate address
-	if !common.IsHexAddress(req.Address) {
+	address, err := parseAddress(c, req.Address)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid Ethereum address",
+			"error": err.Error(),
 		})
 		return
 	}
 
 	// Add address to watch list
-	h.eventService.WatchAddress(req.Address)
+	id := h.eventService.AddTransactionFilter(&events.TransactionFilter{
+		Address:        &address,
+		OnlySuccessful: req.OnlySuccessful,
+	})
 
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"message": "Address added to watch list",
-		"address": req.Address,
+		"id":      id,
+		"address": address.Hex(),
 	})
 }
 
+// watchMethodCalls watches transactions sent to a contract, optionally
+// only those calling a method, which is looked up in the ABI
+func (h *Handler) watchMethodCalls(c *gin.Context, req WatchAddressRequest) {
+	if req.Contract == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "contract is required to watch a method",
+		})
+		return
+	}
+	if req.Method != "" && req.ABI == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "abi is required to watch a method",
+		})
+		return
+	}
+
+	contract, err := parseAddress(c, req.Contract)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	filter := &events.TransactionFilter{
+		ToAddress:      &contract,
+		OnlySuccessful: req.OnlySuccessful,
+	}
+
+	if req.Address != "" {
+		address, err := parseAddress(c, req.Address)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		filter.Address = &address
+	}
+
+	if req.Method != "" {
+		selector, err := ethereum.MethodSelector(req.ABI, req.Method)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		filter.MethodSignature = hexutil.Encode(selector)
+	}
+
+	id := h.eventService.AddTransactionFilter(filter)
+
+	response := gin.H{
+		"success":  true,
+		"message":  "Watching contract calls",
+		"id":       id,
+		"contract": contract.Hex(),
+	}
+	if filter.MethodSignature != "" {
+		response["method"] = req.Method
+		response["methodSignature"] = filter.MethodSignature
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // WatchHighValueTransactionsHandler handles setting up a watch for high-value transactions
 func (h *Handler) WatchHighValueTransactionsHandler(c *gin.Context) {
 	var req WatchHighValueTransactionsRequest
@@ -57,33 +155,117 @@ func (h *Handler) WatchHighValueTransactionsHandler(c *gin.Context) {
 		return
 	}
 
+	if req.OnlySuccessful && !h.eventService.ReceiptsEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errReceiptsDisabled.Error(),
+		})
+		return
+	}
+
 	// Parse ETH value to wei
-	ethValue, ok := new(big.Float).SetString(req.MinValue)
-	if !ok {
+	minValue, err := ethereum.EtherToWei(req.MinValue)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid ETH value",
 		})
 		return
 	}
 
-	// Convert ETH to wei (1 ETH = 10^18 wei)
-	weiValue := new(big.Float).Mul(ethValue, new(big.Float).SetInt(big.NewInt(1000000000000000000)))
-
-	// Convert to integer
-	minValue := new(big.Int)
-	weiValue.Int(minValue)
-
 	// Create filter for high-value transactions
 	filter := &events.TransactionFilter{
-		MinValue: minValue,
+		MinValue:       minValue,
+		OnlySuccessful: req.OnlySuccessful,
 	}
 
 	// Add filter to transaction processor
-	h.eventService.AddTransactionFilter(filter)
+	id := h.eventService.AddTransactionFilter(filter)
 
 	c.JSON(http.StatusOK, gin.H{
 		"success":  true,
 		"message":  "Watching for high-value transactions",
+		"id":       id,
 		"minValue": req.MinValue + " ETH",
 	})
 }
+
+// WatchContractCreationsHandler handles setting up a watch for contract deployments
+func (h *Handler) WatchContractCreationsHandler(c *gin.Context) {
+	id := h.eventService.WatchContractCreations()
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Watching for contract deployments",
+		"id":      id,
+	})
+}
+
+// ListFiltersHandler lists the active transaction filters, a page at a time
+func (h *Handler) ListFiltersHandler(c *gin.Context) {
+	params, err := parsePageParams(c, defaultListLimit, maxListLimit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	entries := h.eventService.TransactionFilters()
+
+	filters := make([]gin.H, 0, len(entries))
+	for _, entry := range entries {
+		filters = append(filters, filterResponse(entry))
+	}
+
+	c.JSON(http.StatusOK, paginate(filters, params))
+}
+
+// RemoveFilterHandler removes a transaction filter by id
+func (h *Handler) RemoveFilterHandler(c *gin.Context) {
+	id := c.Param("id")
+	if !h.eventService.RemoveTransactionFilter(id) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "filter not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Filter removed",
+		"id":      id,
+	})
+}
+
+// filterResponse converts a filter to its JSON representation, omitting unset criteria
+func filterResponse(entry events.FilterEntry) gin.H {
+	f := entry.Filter
+	resp := gin.H{
+		"id": entry.ID,
+	}
+	if f.Address != nil {
+		resp["address"] = f.Address.Hex()
+	}
+	if f.FromAddress != nil {
+		resp["fromAddress"] = f.FromAddress.Hex()
+	}
+	if f.ToAddress != nil {
+		resp["toAddress"] = f.ToAddress.Hex()
+	}
+	if f.MinValue != nil {
+		resp["minValue"] = ethereum.NewAmount(f.MinValue)
+		resp["minValueEth"] = ethereum.WeiToEther(f.MinValue)
+	}
+	if f.OnlyContractTxs {
+		resp["onlyContractTxs"] = true
+	}
+	if f.OnlyContractCreations {
+		resp["onlyContractCreations"] = true
+	}
+	if f.OnlySuccessful {
+		resp["onlySuccessful"] = true
+	}
+	if f.MethodSignature != "" {
+		resp["methodSignature"] = f.MethodSignature
+	}
+	return resp
+}
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
@@ -13,19 +14,118 @@ import (
 type Config struct {
 	Server   ServerConfig
 	Ethereum EthereumConfig
+	Events   EventsConfig
+	Log      LogConfig
 }
 
 // ServerConfig holds configuration for the REST API server
 type ServerConfig struct {
-	Port string
-	Host string
+	Port            string
+	Host            string
+	IdempotencyTTL  time.Duration // How long Idempotency-Key results are remembered
+	StaticDir       string        // Directory of the web interface; empty disables static file serving
+	ScanTimeout     time.Duration // Maximum duration of scan endpoints before returning a partial result
+	ShutdownTimeout time.Duration // Time allowed for in-flight requests and WebSocket clients on shutdown
+	MaxBodyBytes    int64         // Largest accepted request body; 0 disables the limit
+	RPCAllowlist    []string      // JSON-RPC methods that POST /eth/rpc forwards to the node
+	TrustedProxies  []string      // Proxy IPs or CIDRs whose X-Forwarded-For header gives the client IP
+	LongPollTimeout time.Duration // Longest wait of GET /eth/block/latest?wait=true for a new block
+	AdminAPIKey     string        // Key required in the X-API-Key header of admin endpoints; empty disables them
+	NumberEncoding  string        // JSON encoding of balances and values: string, or safe for numbers below 2^53
 }
 
 // EthereumConfig holds configuration for ethereum connection
 type EthereumConfig struct {
-	Provider   string
-	ChainID    int64
-	PrivateKey string
+	Provider    string
+	ChainID     int64
+	PrivateKey  string
+	ClefURL     string // Sign through an external Clef instance instead of PrivateKey
+	ClefAccount string // Clef account used for signing
+	CacheSize   int    // Number of blocks, transactions and receipts to cache; 0 disables caching
+
+	WatchAddresses []string // Addresses monitored from startup, as if POSTed to /monitor/address
+
+	BreakerThreshold int           // Consecutive node failures that open the circuit breaker; 0 disables it
+	BreakerCooldown  time.Duration // How long the breaker stays open before probing the node again
+
+	RetryAttempts       int           // Attempts of read calls failing with transient errors; 1 disables retries
+	RetryInitialBackoff time.Duration // Delay before the first retry, doubled for each further retry
+	RetryMaxBackoff     time.Duration // Maximum delay between retries
+
+	ReconnectMaxBackoff time.Duration // Maximum delay between probes while the provider connection is down
+
+	MaxConcurrentCalls int // RPC calls in flight to the node at once; further calls wait for a slot. 0 disables the limit
+
+	GasLimitBuffer int    // Percentage added to gas estimates, capped at the block gas limit
+	MinGasLimit    uint64 // Lowest gas limit used for estimated transactions
+
+	CongestionSampleBlocks int // Latest blocks averaged by the network congestion endpoint
+
+	ReceiptPollInterval time.Duration // How often a receipt is checked for while waiting for a transaction to be mined
+	ReceiptWaitTimeout  time.Duration // How long to wait for a transaction to be mined before giving up
+
+	CCIPRead bool // Follow EIP-3668 offchain lookups of ENS resolvers, fetching from the HTTPS gateways they name
+}
+
+// EventsConfig holds configuration for the event service
+type EventsConfig struct {
+	EventTypes              []string      // Event types emitted; types left out are never processed. Empty enables all
+	PendingTransactions     bool          // Subscribe to the node's mempool feed
+	Workers                 int           // Number of goroutines executing event handlers
+	QueueSize               int           // Events queued for the workers before new ones are dropped; block transactions wait for room instead
+	ShutdownTimeout         time.Duration // Time allowed for subscription loops and running handlers to finish on shutdown
+	ResumeTTL               time.Duration // How long a disconnected client's subscriptions are kept for resuming
+	RequireAuth             bool          // Require WebSocket clients to sign a challenge with their wallet
+	AuthTimeout             time.Duration // Time allowed to answer the challenge when auth is required
+	BatchWindow             time.Duration // Window over which events are coalesced for clients that opt into batching
+	LogDisconnects          bool          // Log a line with the reason whenever a WebSocket client disconnects
+	TransactionReceipts     bool          // Fetch receipts of mined transactions for the transaction monitor
+	InternalTransactions    bool          // Trace new blocks for internal value transfers (requires debug or trace API)
+	CaptureAllLogs          bool          // Emit every log of each new block, not only subscribed contracts
+	StartBlock              uint64        // Block to backfill from on startup; 0 starts at the chain head
+	CatchUpBatchSize        int           // Blocks fetched per batched RPC call when catching up
+	CatchUpBatchesPerSecond int           // Maximum batched calls per second when catching up; 0 is unlimited
+	MaxWatchedBalances      int           // Maximum addresses whose balance a WebSocket client can watch
+	MaxPendingWatches       int           // Maximum pending transaction filters a WebSocket client can watch
+	DeadLetterSize          int           // Failed event deliveries kept for the admin API; 0 disables the dead-letter log
+	MaxMessagesPerSecond    int           // Messages per second accepted from each WebSocket client; 0 is unlimited
+	MaxSubscriptions        int           // Contract subscriptions each WebSocket client can hold; 0 is unlimited
+	MaxBackfillBlocks       int           // Largest block range replayed for a subscription with fromBlock; 0 is unlimited
+	ReplayBufferSize        int           // Recent events kept for clients reconnecting with since; 0 disables replay
+	SubscriptionBufferSize  int           // Notifications buffered per node subscription while the listener is busy
+	PauseBufferSize         int           // Events held while broadcasting is paused, delivered on resume; 0 drops them
+	RestrictContracts       bool          // Only allow contract subscriptions to ContractAllowlist
+	ContractAllowlist       []string      // Contracts clients may subscribe to when restricted; empty allows all
+	NATSURL                 string        // NATS server that broadcast events are also published to; empty disables the sink
+	NATSSubjectPrefix       string        // Events are published on <prefix>.<event type>
+}
+
+// LogConfig holds configuration for logging
+type LogConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // text or json
+}
+
+// defaultRPCAllowlist are the read-only methods forwarded by the JSON-RPC passthrough by default
+var defaultRPCAllowlist = []string{
+	"eth_blockNumber",
+	"eth_chainId",
+	"eth_gasPrice",
+	"eth_maxPriorityFeePerGas",
+	"eth_feeHistory",
+	"eth_getBalance",
+	"eth_getCode",
+	"eth_getStorageAt",
+	"eth_getTransactionCount",
+	"eth_getBlockByNumber",
+	"eth_getBlockByHash",
+	"eth_getTransactionByHash",
+	"eth_getTransactionReceipt",
+	"eth_getLogs",
+	"eth_call",
+	"eth_estimateGas",
+	"net_version",
+	"web3_clientVersion",
 }
 
 // LoadConfig loads the configuration from file and environment variables
@@ -42,8 +142,63 @@ func LoadConfig() (*Config, error) {
 	// Set default values
 	viper.SetDefault("server.port", "8080")
 	viper.SetDefault("server.host", "localhost")
+	viper.SetDefault("server.idempotencyTTL", "24h")
+	viper.SetDefault("server.shutdownTimeout", "10s")
+	viper.SetDefault("server.scanTimeout", "30s")
+	viper.SetDefault("server.longPollTimeout", "30s")
+	viper.SetDefault("server.adminAPIKey", "")
+	viper.SetDefault("server.numberEncoding", "string")
+	viper.SetDefault("server.staticDir", "./static")
+	viper.SetDefault("server.maxBodyBytes", 512*1024)
+	viper.SetDefault("server.rpcAllowlist", defaultRPCAllowlist)
+	viper.SetDefault("server.trustedProxies", []string{"127.0.0.1", "::1"})
 	viper.SetDefault("ethereum.provider", "ws://localhost:8545")
 	viper.SetDefault("ethereum.chainID", 1)
+	viper.SetDefault("ethereum.cacheSize", 1024)
+	viper.SetDefault("ethereum.breakerThreshold", 5)
+	viper.SetDefault("ethereum.breakerCooldown", "30s")
+	viper.SetDefault("ethereum.retryAttempts", 3)
+	viper.SetDefault("ethereum.retryInitialBackoff", "200ms")
+	viper.SetDefault("ethereum.retryMaxBackoff", "2s")
+	viper.SetDefault("ethereum.maxConcurrentCalls", 32)
+	viper.SetDefault("ethereum.reconnectMaxBackoff", "30s")
+	viper.SetDefault("ethereum.gasLimitBuffer", 20)
+	viper.SetDefault("ethereum.minGasLimit", 21000)
+	viper.SetDefault("ethereum.congestionSampleBlocks", 20)
+	viper.SetDefault("ethereum.receiptPollInterval", "4s")
+	viper.SetDefault("ethereum.receiptWaitTimeout", "5m")
+	viper.SetDefault("ethereum.ccipRead", false)
+	viper.SetDefault("events.eventTypes", []string{"new_block", "new_transaction", "contract_event", "pending_transaction", "internal_transaction"})
+	viper.SetDefault("events.pendingTransactions", false)
+	viper.SetDefault("events.workers", 16)
+	viper.SetDefault("events.queueSize", 4096)
+	viper.SetDefault("events.shutdownTimeout", "5s")
+	viper.SetDefault("events.resumeTTL", "5m")
+	viper.SetDefault("events.requireAuth", false)
+	viper.SetDefault("events.authTimeout", "30s")
+	viper.SetDefault("events.captureAllLogs", false)
+	viper.SetDefault("events.internalTransactions", false)
+	viper.SetDefault("events.transactionReceipts", false)
+	viper.SetDefault("events.logDisconnects", false)
+	viper.SetDefault("events.batchWindow", "100ms")
+	viper.SetDefault("events.startBlock", 0)
+	viper.SetDefault("events.catchUpBatchSize", 20)
+	viper.SetDefault("events.catchUpBatchesPerSecond", 2)
+	viper.SetDefault("events.maxWatchedBalances", 20)
+	viper.SetDefault("events.maxPendingWatches", 10)
+	viper.SetDefault("events.deadLetterSize", 0)
+	viper.SetDefault("events.maxMessagesPerSecond", 10)
+	viper.SetDefault("events.maxSubscriptions", 50)
+	viper.SetDefault("events.maxBackfillBlocks", 100000)
+	viper.SetDefault("events.replayBufferSize", 1000)
+	viper.SetDefault("events.subscriptionBufferSize", 64)
+	viper.SetDefault("events.pauseBufferSize", 10000)
+	viper.SetDefault("events.restrictContracts", false)
+	viper.SetDefault("events.contractAllowlist", []string{})
+	viper.SetDefault("events.natsURL", "")
+	viper.SetDefault("events.natsSubjectPrefix", "web3.events")
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.format", "text")
 
 	// Read config file
 	if err := viper.ReadInConfig(); err != nil {
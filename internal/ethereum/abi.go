@@ -0,0 +1,212 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrInvalidABI is returned when an ABI definition can't be parsed
+var ErrInvalidABI = errors.New("invalid ABI")
+
+// ErrUnknownMethod is returned when a method isn't defined in an ABI
+var ErrUnknownMethod = errors.New("method not found in ABI")
+
+// MethodSelector returns the 4-byte selector of a method of an ABI. The
+// method is given by name, with overloads named as go-ethereum names them
+// (transfer, transfer0, ...), or by its signature, e.g. transfer(address,uint256).
+func MethodSelector(abiJSON, method string) ([]byte, error) {
+	parsed, err := parseABI(abiJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	if m, ok := parsed.Methods[method]; ok {
+		return m.ID, nil
+	}
+	for _, m := range parsed.Methods {
+		if m.Sig == method {
+			return m.ID, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownMethod, method)
+}
+
+// parseABI parses a JSON ABI definition
+func parseABI(abiJSON string) (abi.ABI, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("%w: %v", ErrInvalidABI, err)
+	}
+	return parsed, nil
+}
+
+// packMethodCall ABI-encodes a method call, converting loosely typed
+// arguments (e.g. decoded from JSON) to the types the ABI expects
+func packMethodCall(parsed abi.ABI, method string, args []interface{}) ([]byte, error) {
+	m, ok := parsed.Methods[method]
+	if !ok {
+		return nil, fmt.Errorf("method %q not found in ABI", method)
+	}
+	if len(args) != len(m.Inputs) {
+		return nil, fmt.Errorf("method %q expects %d arguments, got %d", method, len(m.Inputs), len(args))
+	}
+
+	converted := make([]interface{}, len(args))
+	for i, input := range m.Inputs {
+		value, err := coerceABIValue(input.Type, args[i])
+		if err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %w", i, input.Name, err)
+		}
+		converted[i] = value
+	}
+
+	data, err := parsed.Pack(method, converted...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack method call: %w", err)
+	}
+	return data, nil
+}
+
+// coerceABIValue converts a value to the Go type used by the ABI encoder for t.
+// Values that already have the right type are returned unchanged.
+func coerceABIValue(t abi.Type, value interface{}) (interface{}, error) {
+	goType := t.GetType()
+	if value != nil && reflect.TypeOf(value) == goType {
+		return value, nil
+	}
+
+	switch t.T {
+	case abi.AddressTy:
+		s, ok := value.(string)
+		if !ok || !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("expected address, got %v", value)
+		}
+		return common.HexToAddress(s), nil
+
+	case abi.IntTy, abi.UintTy:
+		n, err := toBigInt(value)
+		if err != nil {
+			return nil, err
+		}
+		if goType == reflect.TypeOf(&big.Int{}) {
+			return n, nil
+		}
+		// Sized integers (up to 64 bits) use native Go types
+		out := reflect.New(goType).Elem()
+		if t.T == abi.UintTy {
+			if n.Sign() < 0 || !n.IsUint64() || out.OverflowUint(n.Uint64()) {
+				return nil, fmt.Errorf("value %s out of range for %s", n, t.String())
+			}
+			out.SetUint(n.Uint64())
+		} else {
+			if !n.IsInt64() || out.OverflowInt(n.Int64()) {
+				return nil, fmt.Errorf("value %s out of range for %s", n, t.String())
+			}
+			out.SetInt(n.Int64())
+		}
+		return out.Interface(), nil
+
+	case abi.BoolTy:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected bool, got %v", value)
+		}
+		return b, nil
+
+	case abi.StringTy:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string, got %v", value)
+		}
+		return s, nil
+
+	case abi.BytesTy:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected hex bytes, got %v", value)
+		}
+		return hexutil.Decode(s)
+
+	case abi.FixedBytesTy:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected hex bytes, got %v", value)
+		}
+		raw, err := hexutil.Decode(s)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) != t.Size {
+			return nil, fmt.Errorf("expected %d bytes, got %d", t.Size, len(raw))
+		}
+		out := reflect.New(goType).Elem()
+		reflect.Copy(out, reflect.ValueOf(raw))
+		return out.Interface(), nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected array, got %v", value)
+		}
+		if t.T == abi.ArrayTy && len(items) != t.Size {
+			return nil, fmt.Errorf("expected %d elements, got %d", t.Size, len(items))
+		}
+
+		var out reflect.Value
+		if t.T == abi.SliceTy {
+			out = reflect.MakeSlice(goType, len(items), len(items))
+		} else {
+			out = reflect.New(goType).Elem()
+		}
+		for i, item := range items {
+			elem, err := coerceABIValue(*t.Elem, item)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			out.Index(i).Set(reflect.ValueOf(elem))
+		}
+		return out.Interface(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported argument type %s", t.String())
+}
+
+// toBigInt converts a decimal or 0x-prefixed hex string or JSON number to a big.Int
+func toBigInt(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case string:
+		n, ok := new(big.Int).SetString(v, 0)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	case json.Number:
+		n, ok := new(big.Int).SetString(v.String(), 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid integer %q", v)
+		}
+		return n, nil
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("invalid integer %v", v)
+		}
+		return big.NewInt(int64(v)), nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case uint64:
+		return new(big.Int).SetUint64(v), nil
+	}
+	return nil, fmt.Errorf("expected integer, got %v", value)
+}
@@ -0,0 +1,88 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+)
+
+// ErrAccessListUnsupported is returned when the chain doesn't accept type-1 transactions
+var ErrAccessListUnsupported = errors.New("connected chain does not support EIP-2930 access list transactions")
+
+// SendAccessListTransaction sends an EIP-2930 (type-1) transaction with an access list.
+// If accessList is nil, it is generated by the node via eth_createAccessList.
+func (c *Client) SendAccessListTransaction(ctx context.Context, to string, amount *big.Int, data []byte, accessList types.AccessList) (string, error) {
+	toAddress := common.HexToAddress(to)
+
+	if accessList == nil {
+		generated, err := c.CreateAccessList(ctx, to, amount, data)
+		if err != nil {
+			return "", err
+		}
+		accessList = generated
+	}
+
+	// A legacy gas price makes this a type-1 transaction
+	gasPrice, err := c.SuggestGasPrice(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+
+	txHash, err := c.SendTransactionWithOptions(ctx, TxOptions{
+		To:         &toAddress,
+		Value:      amount,
+		Data:       data,
+		GasPrice:   gasPrice,
+		AccessList: accessList,
+	})
+	if err != nil && isTxTypeNotSupported(err) {
+		return "", ErrAccessListUnsupported
+	}
+	return txHash, err
+}
+
+// CreateAccessList asks the node to generate the access list for a transaction
+func (c *Client) CreateAccessList(ctx context.Context, to string, amount *big.Int, data []byte) (_ types.AccessList, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	toAddress := common.HexToAddress(to)
+
+	var accessList *types.AccessList
+	var vmErr string
+	err = c.limit(ctx, func() (err error) {
+		accessList, _, vmErr, err = gethclient.New(c.Client.Client()).CreateAccessList(ctx, ethereum.CallMsg{
+			From:  c.fromAddress,
+			To:    &toAddress,
+			Value: amount,
+			Data:  data,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access list: %w", err)
+	}
+	if vmErr != "" {
+		return nil, fmt.Errorf("failed to create access list: execution failed: %s", vmErr)
+	}
+	if accessList == nil {
+		return types.AccessList{}, nil
+	}
+	return *accessList, nil
+}
+
+// isTxTypeNotSupported reports whether the node rejected a transaction for its type
+func isTxTypeNotSupported(err error) bool {
+	return errors.Is(err, types.ErrTxTypeNotSupported) ||
+		strings.Contains(err.Error(), types.ErrTxTypeNotSupported.Error())
+}
@@ -0,0 +1,64 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+)
+
+// NumberEncoding selects how amounts, such as balances and transaction
+// values, are encoded in JSON
+type NumberEncoding string
+
+// Number encodings
+const (
+	NumberEncodingString NumberEncoding = "string" // Always a decimal string
+	NumberEncodingSafe   NumberEncoding = "safe"   // A JSON number up to 2^53-1, a decimal string beyond
+)
+
+// maxSafeInteger is the largest integer a JavaScript number holds exactly
+var maxSafeInteger = big.NewInt(1<<53 - 1)
+
+// numberEncoding is the encoding used by Amount, set once at startup
+var numberEncoding atomic.Value
+
+// SetNumberEncoding sets how amounts are encoded in JSON. An empty encoding
+// keeps the default of always encoding them as strings.
+func SetNumberEncoding(encoding string) error {
+	switch NumberEncoding(encoding) {
+	case "", NumberEncodingString:
+		numberEncoding.Store(NumberEncodingString)
+	case NumberEncodingSafe:
+		numberEncoding.Store(NumberEncodingSafe)
+	default:
+		return fmt.Errorf("invalid number encoding %q: must be string or safe", encoding)
+	}
+	return nil
+}
+
+// Amount is an integer amount, in wei or token base units, encoded in JSON
+// according to the configured NumberEncoding
+type Amount struct {
+	value *big.Int
+}
+
+// NewAmount wraps an amount for JSON encoding. A nil value encodes as 0.
+func NewAmount(value *big.Int) Amount {
+	return Amount{value: value}
+}
+
+// MarshalJSON encodes the amount as a decimal string or, with the safe
+// encoding, as a number when it fits a JavaScript number exactly
+func (a Amount) MarshalJSON() ([]byte, error) {
+	value := a.value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	encoding, _ := numberEncoding.Load().(NumberEncoding)
+	if encoding == NumberEncodingSafe && value.CmpAbs(maxSafeInteger) <= 0 {
+		return []byte(value.String()), nil
+	}
+	return []byte(strconv.Quote(value.String())), nil
+}
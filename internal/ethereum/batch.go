@@ -0,0 +1,245 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errBatchRejected is returned when the node doesn't accept a batched call
+var errBatchRejected = errors.New("batched call rejected")
+
+// rpcBlockBody holds the block body fields of an eth_getBlockByNumber response
+type rpcBlockBody struct {
+	Transactions []*types.Transaction `json:"transactions"`
+	Withdrawals  []*types.Withdrawal  `json:"withdrawals,omitempty"`
+}
+
+// GetBlocksByNumber fetches several blocks with full transactions in a single
+// batched RPC round trip. Blocks are returned in the order requested.
+// Uncle headers are not fetched.
+func (c *Client) GetBlocksByNumber(ctx context.Context, numbers []uint64) (_ []*types.Block, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	raw := make([]json.RawMessage, len(numbers))
+	reqs := make([]rpc.BatchElem, len(numbers))
+	for i, number := range numbers {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(number), true},
+			Result: &raw[i],
+		}
+	}
+
+	if err := c.retry(ctx, func() error { return c.Client.Client().BatchCallContext(ctx, reqs) }); err != nil {
+		return nil, fmt.Errorf("failed to batch fetch blocks: %w", err)
+	}
+
+	blocks := make([]*types.Block, len(numbers))
+	for i, req := range reqs {
+		if req.Error != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", numbers[i], req.Error)
+		}
+
+		block, err := decodeBlock(raw[i])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode block %d: %w", numbers[i], err)
+		}
+		blocks[i] = block
+	}
+
+	return blocks, nil
+}
+
+// decodeBlock decodes a block with full transactions from its JSON-RPC representation
+func decodeBlock(raw json.RawMessage) (*types.Block, error) {
+	var head *types.Header
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, fmt.Errorf("block not found")
+	}
+
+	var body rpcBlockBody
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return nil, err
+	}
+
+	return types.NewBlockWithHeader(head).WithBody(types.Body{
+		Transactions: body.Transactions,
+		Withdrawals:  body.Withdrawals,
+	}), nil
+}
+
+// GetBlockReceipts returns the receipts of every transaction in a block, in
+// transaction order. It uses eth_getBlockReceipts and falls back to batched
+// eth_getTransactionReceipt calls on nodes that don't support it.
+func (c *Client) GetBlockReceipts(ctx context.Context, block *types.Block) (_ []*types.Receipt, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var receipts []*types.Receipt
+	err = c.limit(ctx, func() (err error) {
+		receipts, err = c.Client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+		return err
+	})
+	if isMethodNotFound(err) {
+		receipts, err = c.batchTransactionReceipts(ctx, block.Transactions())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block receipts: %w", err)
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("failed to get block receipts: got %d receipts for %d transactions", len(receipts), len(block.Transactions()))
+	}
+
+	// Receipts of recent blocks are dropped if a reorg replaces the block
+	if c.isReorgSafe(ctx, block.NumberU64()) {
+		for _, receipt := range receipts {
+			c.receiptCache.Add(receipt.TxHash, receipt)
+		}
+	}
+	return receipts, nil
+}
+
+// GetReceipts returns the receipts of several transactions, in the order of
+// txHashes, fetching those not cached in a single batched call. Transactions
+// that aren't mined have a nil receipt. Nodes that reject batched calls are
+// asked for each receipt in turn.
+func (c *Client) GetReceipts(ctx context.Context, txHashes []string) (_ []*types.Receipt, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	receipts := make([]*types.Receipt, len(txHashes))
+	var missing []common.Hash
+	var indexes []int
+	for i, txHash := range txHashes {
+		hash := common.HexToHash(txHash)
+		if receipt, ok := c.receiptCache.Get(hash); ok {
+			receipts[i] = receipt
+			continue
+		}
+		missing = append(missing, hash)
+		indexes = append(indexes, i)
+	}
+	if len(missing) == 0 {
+		return receipts, nil
+	}
+
+	fetched, err := c.batchReceipts(ctx, missing)
+	if errors.Is(err, errBatchRejected) {
+		fetched, err = c.sequentialReceipts(ctx, missing)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipts: %w", err)
+	}
+
+	head := c.head.Load()
+	for j, receipt := range fetched {
+		if receipt == nil {
+			continue
+		}
+		if receipt.BlockNumber != nil && receipt.BlockNumber.Uint64()+reorgSafeDepth <= head {
+			c.receiptCache.Add(missing[j], receipt)
+		}
+		receipts[indexes[j]] = receipt
+	}
+	return receipts, nil
+}
+
+// batchTransactionReceipts fetches the receipts of several transactions in a single batched call
+func (c *Client) batchTransactionReceipts(ctx context.Context, txs types.Transactions) ([]*types.Receipt, error) {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+
+	receipts, err := c.batchReceipts(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+	for i, receipt := range receipts {
+		if receipt == nil {
+			return nil, fmt.Errorf("receipt of %s not found", hashes[i].Hex())
+		}
+	}
+	return receipts, nil
+}
+
+// batchReceipts fetches receipts in a single batched call, leaving those of
+// transactions that aren't mined nil
+func (c *Client) batchReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(hashes))
+	reqs := make([]rpc.BatchElem, len(hashes))
+	for i, hash := range hashes {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+
+	if err := c.retry(ctx, func() error { return c.Client.Client().BatchCallContext(ctx, reqs) }); err != nil {
+		if isBatchUnsupported(err) {
+			return nil, fmt.Errorf("%w: %w", errBatchRejected, err)
+		}
+		return nil, err
+	}
+	for i, req := range reqs {
+		if req.Error != nil {
+			return nil, fmt.Errorf("receipt of %s: %w", hashes[i].Hex(), req.Error)
+		}
+	}
+	return receipts, nil
+}
+
+// sequentialReceipts fetches receipts one call at a time, leaving those of
+// transactions that aren't mined nil
+func (c *Client) sequentialReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(hashes))
+	for i, hash := range hashes {
+		err := c.retry(ctx, func() (err error) {
+			receipts[i], err = c.Client.TransactionReceipt(ctx, hash)
+			return err
+		})
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("receipt of %s: %w", hash.Hex(), err)
+		}
+	}
+	return receipts, nil
+}
+
+// isBatchUnsupported reports whether the node rejected a batched call as a
+// whole, either with a JSON-RPC error or with a response that isn't a batch
+func isBatchUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	var typeErr *json.UnmarshalTypeError
+	return errors.As(err, &rpcErr) || errors.As(err, &typeErr)
+}
+
+// isMethodNotFound reports whether the node rejected the RPC method as unknown
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32601
+}
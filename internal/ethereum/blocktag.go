@@ -0,0 +1,109 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Block tags accepted in place of a block number
+const (
+	BlockTagLatest    = "latest"
+	BlockTagSafe      = "safe"
+	BlockTagFinalized = "finalized"
+)
+
+// ErrInvalidBlockTag is returned for a block tag other than latest, safe or finalized
+var ErrInvalidBlockTag = errors.New("invalid block tag: must be latest, safe or finalized")
+
+// ErrBlockTagUnsupported is returned when the node has no safe or finalized
+// block, as on chains that have not gone through the merge
+var ErrBlockTagUnsupported = errors.New("block tag not supported by the node (pre-merge chain?)")
+
+// blockTagNumber returns the RPC block number of a block tag
+func blockTagNumber(tag string) (*big.Int, error) {
+	switch tag {
+	case BlockTagLatest:
+		return nil, nil
+	case BlockTagSafe:
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case BlockTagFinalized:
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	}
+	return nil, ErrInvalidBlockTag
+}
+
+// isBlockTagUnsupported reports whether an error means the node does not know
+// the requested block tag. Nodes either return no block or reject the tag.
+func isBlockTagUnsupported(err error) bool {
+	if errors.Is(err, ethereum.NotFound) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "pre-merge") ||
+		strings.Contains(msg, "block not found") ||
+		strings.Contains(msg, "not supported") ||
+		strings.Contains(msg, "invalid block tag") ||
+		strings.Contains(msg, "unknown block")
+}
+
+// GetBlockByTag returns the latest, safe or finalized block
+func (c *Client) GetBlockByTag(ctx context.Context, tag string) (_ *types.Block, err error) {
+	number, err := blockTagNumber(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var block *types.Block
+	err = c.retry(ctx, func() (err error) {
+		block, err = c.Client.BlockByNumber(ctx, number)
+		return err
+	})
+	if err != nil {
+		if tag != BlockTagLatest && isBlockTagUnsupported(err) {
+			return nil, fmt.Errorf("%w: %s", ErrBlockTagUnsupported, tag)
+		}
+		return nil, fmt.Errorf("failed to get %s block: %w", tag, err)
+	}
+	return block, nil
+}
+
+// GetBalanceAtTag returns the balance of an address at the latest, safe or finalized block
+func (c *Client) GetBalanceAtTag(ctx context.Context, address common.Address, tag string) (_ *big.Int, err error) {
+	number, err := blockTagNumber(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var balance *big.Int
+	err = c.retry(ctx, func() (err error) {
+		balance, err = c.Client.BalanceAt(ctx, address, number)
+		return err
+	})
+	if err != nil {
+		if tag != BlockTagLatest && isBlockTagUnsupported(err) {
+			return nil, fmt.Errorf("%w: %s", ErrBlockTagUnsupported, tag)
+		}
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance, nil
+}
@@ -0,0 +1,101 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+)
+
+// BlockByTimestamp returns the number of the block whose timestamp is closest
+// to targetUnix, found by binary search over block headers. Times before the
+// genesis block or after the latest block give the first or latest block.
+func (c *Client) BlockByTimestamp(ctx context.Context, targetUnix int64) (_ uint64, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var head uint64
+	err = c.retry(ctx, func() (err error) {
+		head, err = c.Client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+	c.head.Store(head)
+
+	target := uint64(max(targetUnix, 0))
+	lo, hi := uint64(0), head
+	loTime, err := c.blockTime(ctx, lo)
+	if err != nil {
+		return 0, err
+	}
+	if target <= loTime {
+		return lo, nil
+	}
+	hiTime, err := c.blockTime(ctx, hi)
+	if err != nil {
+		return 0, err
+	}
+	if target >= hiTime {
+		return hi, nil
+	}
+
+	// Block lo is at or before the target and block hi after it
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		midTime, err := c.blockTime(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if midTime <= target {
+			lo, loTime = mid, midTime
+		} else {
+			hi, hiTime = mid, midTime
+		}
+	}
+
+	if target-loTime <= hiTime-target {
+		return lo, nil
+	}
+	return hi, nil
+}
+
+// GetBlockTime returns the timestamp of a block
+func (c *Client) GetBlockTime(ctx context.Context, blockNumber uint64) (_ uint64, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	return c.blockTime(ctx, blockNumber)
+}
+
+// blockTime returns the timestamp of a block from its header. Timestamps of
+// blocks at least reorgSafeDepth below the known head are cached.
+func (c *Client) blockTime(ctx context.Context, blockNumber uint64) (uint64, error) {
+	if timestamp, ok := c.timeCache.Get(blockNumber); ok {
+		return timestamp, nil
+	}
+
+	var timestamp uint64
+	err := c.retry(ctx, func() error {
+		head, err := c.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+		if err != nil {
+			return err
+		}
+		timestamp = head.Time
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get header of block %d: %w", blockNumber, err)
+	}
+
+	if blockNumber+reorgSafeDepth <= c.head.Load() {
+		c.timeCache.Add(blockNumber, timestamp)
+	}
+	return timestamp, nil
+}
@@ -0,0 +1,177 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrCircuitOpen is returned without contacting the node while the circuit breaker is open
+var ErrCircuitOpen = errors.New("ethereum node unavailable: circuit breaker open")
+
+// breakerMetrics exposes the circuit breaker state and counters
+var breakerMetrics = expvar.NewMap("ethereum_circuit_breaker")
+
+// Circuit breaker states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// circuitBreaker short-circuits RPC calls after consecutive node failures.
+// Once the cooldown has passed a single probe call is let through; its outcome
+// closes the circuit again or re-opens it for another cooldown.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// newCircuitBreaker creates a circuit breaker opening after threshold
+// consecutive failures. A threshold of zero or less disables it.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	b := &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     CircuitClosed,
+	}
+	breakerMetrics.Set("state", expvar.Func(func() interface{} {
+		return b.State()
+	}))
+	breakerMetrics.Set("consecutive_failures", expvar.Func(func() interface{} {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.failures
+	}))
+	return b
+}
+
+// Allow returns ErrCircuitOpen if a call must not be attempted, and otherwise
+// whether the call is the probe of a half-open circuit
+func (b *circuitBreaker) Allow() (bool, error) {
+	if b.threshold <= 0 {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			breakerMetrics.Add("rejected_calls", 1)
+			return false, ErrCircuitOpen
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true, nil
+	case CircuitHalfOpen:
+		if b.probing {
+			breakerMetrics.Add("rejected_calls", 1)
+			return false, ErrCircuitOpen
+		}
+		b.probing = true
+		return true, nil
+	}
+	return false, nil
+}
+
+// Record updates the breaker with the outcome of an allowed call made with
+// ctx, probe being what Allow returned for it. Errors telling nothing about
+// the node are ignored: those of the caller's own context and of a nested
+// call rejected by the breaker. While half-open, only the probe's outcome
+// counts and only a response from the node closes the circuit; a probe
+// failing before reaching it lets the next call probe instead.
+func (b *circuitBreaker) Record(ctx context.Context, probe bool, err error) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	} else if b.state == CircuitHalfOpen {
+		// Started before the circuit opened, the probe decides
+		return
+	}
+	if errors.Is(err, ErrCircuitOpen) || (ctx.Err() != nil && errors.Is(err, ctx.Err())) {
+		return
+	}
+	if !isNodeFailure(err) {
+		if b.state == CircuitHalfOpen && !isNodeResponse(err) {
+			return
+		}
+		b.failures = 0
+		b.state = CircuitClosed
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || (b.state == CircuitClosed && b.failures >= b.threshold) {
+		b.state = CircuitOpen
+		b.openedAt = time.Now()
+		breakerMetrics.Add("trips", 1)
+	}
+}
+
+// isNodeResponse reports whether a call ended with an answer from the node:
+// success, a JSON-RPC error or a not-found result
+func isNodeResponse(err error) bool {
+	var rpcErr rpc.Error
+	return err == nil || errors.As(err, &rpcErr) || errors.Is(err, ethereum.NotFound)
+}
+
+// State returns the current breaker state
+func (b *circuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.cooldown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// isNodeFailure reports whether an error means the node could not serve the
+// call (unreachable, timing out or failing). JSON-RPC error responses such as
+// reverts and not-found results come from a healthy node and do not count.
+func isNodeFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, rpc.ErrClientQuit):
+		return true
+	case errors.As(err, &netErr):
+		return true
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode >= 500
+	}
+	return false
+}
+
+// BreakerState returns the state of the RPC circuit breaker
+func (c *Client) BreakerState() string {
+	return c.breaker.State()
+}
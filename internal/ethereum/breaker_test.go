@@ -0,0 +1,80 @@
+package ethereum
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+// openTestBreaker returns a breaker that has tripped and whose cooldown has passed
+func openTestBreaker(t *testing.T) *circuitBreaker {
+	t.Helper()
+
+	b := newCircuitBreaker(1, time.Millisecond)
+	probe, err := b.Allow()
+	if err != nil || probe {
+		t.Fatalf("Allow on a closed breaker = %v, %v", probe, err)
+	}
+	b.Record(context.Background(), probe, io.EOF)
+	if b.state != CircuitOpen {
+		t.Fatalf("state after a failure = %s, want %s", b.state, CircuitOpen)
+	}
+	time.Sleep(2 * time.Millisecond)
+	return b
+}
+
+func TestBreakerRejectedNestedCallKeepsProbe(t *testing.T) {
+	b := openTestBreaker(t)
+	ctx := context.Background()
+
+	probe, err := b.Allow()
+	if err != nil || !probe {
+		t.Fatalf("first Allow after the cooldown = %v, %v, want the probe", probe, err)
+	}
+
+	// A nested call made by the probe is rejected, and the probe fails with its error
+	if _, err := b.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("nested Allow = %v, want ErrCircuitOpen", err)
+	}
+	if _, err := b.Allow(); err != ErrCircuitOpen {
+		t.Fatalf("concurrent Allow while probing = %v, want ErrCircuitOpen", err)
+	}
+
+	b.Record(ctx, probe, ErrCircuitOpen)
+	if _, err := b.Allow(); err != nil {
+		t.Fatalf("Allow after the probe ended = %v, want the next probe", err)
+	}
+}
+
+func TestBreakerLateCallDoesNotEndProbe(t *testing.T) {
+	b := newCircuitBreaker(1, time.Millisecond)
+	ctx := context.Background()
+
+	// Started while the circuit was closed, finishing after it opened
+	late, err := b.Allow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing, _ := b.Allow()
+	b.Record(ctx, failing, io.EOF)
+	time.Sleep(2 * time.Millisecond)
+
+	probe, err := b.Allow()
+	if err != nil || !probe {
+		t.Fatalf("Allow after the cooldown = %v, %v, want the probe", probe, err)
+	}
+
+	b.Record(ctx, late, nil)
+	if b.state != CircuitHalfOpen {
+		t.Errorf("state after a late success = %s, want %s", b.state, CircuitHalfOpen)
+	}
+	if _, err := b.Allow(); err != ErrCircuitOpen {
+		t.Errorf("Allow while the probe is in flight = %v, want ErrCircuitOpen", err)
+	}
+
+	b.Record(ctx, probe, nil)
+	if b.state != CircuitClosed {
+		t.Errorf("state after the probe succeeded = %s, want %s", b.state, CircuitClosed)
+	}
+}
@@ -0,0 +1,187 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrBundleSimulationUnsupported is returned when the node can't simulate a sequence of calls
+var ErrBundleSimulationUnsupported = errors.New("node supports neither eth_simulateV1 nor eth_callMany")
+
+// SimResult is the outcome of one call of a simulated bundle
+type SimResult struct {
+	Success    bool
+	ReturnData []byte
+	GasUsed    uint64       // Zero when the node doesn't report it (eth_callMany)
+	Revert     *RevertError // Set when the call reverted
+	Error      string       // Failure other than a revert, e.g. out of gas
+}
+
+// simulateV1Call is a call result of eth_simulateV1
+type simulateV1Call struct {
+	ReturnData hexutil.Bytes  `json:"returnData"`
+	Status     hexutil.Uint64 `json:"status"`
+	GasUsed    hexutil.Uint64 `json:"gasUsed"`
+	Error      *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Data    string `json:"data"`
+	} `json:"error"`
+}
+
+// callManyResult is a call result of eth_callMany
+type callManyResult struct {
+	Value string `json:"value"`
+	Error string `json:"error"`
+}
+
+// SimulateBundle executes calls in sequence on top of the latest block, each
+// seeing the state changes of the ones before, and reports the outcome of
+// each call. Calls without From are made from the client's address. The node
+// must support eth_simulateV1 (geth) or eth_callMany (Erigon, Nethermind).
+func (c *Client) SimulateBundle(ctx context.Context, calls []ethereum.CallMsg) (_ []SimResult, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	args := make([]map[string]interface{}, len(calls))
+	for i, call := range calls {
+		if call.From == (common.Address{}) {
+			call.From = c.fromAddress
+		}
+		args[i] = callArg(call)
+	}
+
+	results, err := c.simulateV1(ctx, args)
+	if isMethodNotFound(err) {
+		results, err = c.callMany(ctx, args)
+		if isMethodNotFound(err) {
+			return nil, ErrBundleSimulationUnsupported
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate bundle: %w", err)
+	}
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("failed to simulate bundle: node returned %d results for %d calls", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// simulateV1 simulates the calls as one block with eth_simulateV1
+func (c *Client) simulateV1(ctx context.Context, args []map[string]interface{}) ([]SimResult, error) {
+	request := map[string]interface{}{
+		"blockStateCalls": []interface{}{map[string]interface{}{"calls": args}},
+	}
+
+	var blocks []struct {
+		Calls []simulateV1Call `json:"calls"`
+	}
+	err := c.retry(ctx, func() error {
+		return c.Client.Client().CallContext(ctx, &blocks, "eth_simulateV1", request, "latest")
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) != 1 {
+		return nil, fmt.Errorf("node returned %d simulated blocks", len(blocks))
+	}
+
+	results := make([]SimResult, len(blocks[0].Calls))
+	for i, call := range blocks[0].Calls {
+		result := SimResult{
+			Success:    call.Status == 1,
+			ReturnData: call.ReturnData,
+			GasUsed:    uint64(call.GasUsed),
+		}
+		if call.Error != nil {
+			if data, err := hexutil.Decode(call.Error.Data); err == nil {
+				result.Revert = newRevertError(data)
+			} else {
+				result.Error = call.Error.Message
+			}
+		} else if !result.Success {
+			result.Revert = newRevertError(call.ReturnData)
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// callMany simulates the calls as one bundle with eth_callMany
+func (c *Client) callMany(ctx context.Context, args []map[string]interface{}) ([]SimResult, error) {
+	bundles := []interface{}{map[string]interface{}{"transactions": args}}
+
+	var outcomes [][]callManyResult
+	err := c.retry(ctx, func() error {
+		return c.Client.Client().CallContext(ctx, &outcomes, "eth_callMany", bundles, map[string]interface{}{"blockNumber": "latest"})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(outcomes) != 1 {
+		return nil, fmt.Errorf("node returned %d simulated bundles", len(outcomes))
+	}
+
+	results := make([]SimResult, len(outcomes[0]))
+	for i, outcome := range outcomes[0] {
+		data, _ := hexutil.Decode(outcome.Value)
+		switch {
+		case outcome.Error == "":
+			results[i] = SimResult{Success: true, ReturnData: data}
+		case len(data) > 0:
+			results[i] = SimResult{Revert: newRevertError(data)}
+		default:
+			results[i] = SimResult{Error: outcome.Error}
+		}
+	}
+	return results, nil
+}
+
+// newRevertError creates a RevertError from revert data, decoding an Error(string) reason
+func newRevertError(data []byte) *RevertError {
+	revertErr := &RevertError{Data: data}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		revertErr.Reason = reason
+	}
+	return revertErr
+}
+
+// callArg encodes a call in the JSON-RPC transaction call object format
+func callArg(msg ethereum.CallMsg) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+	}
+	if msg.To != nil {
+		arg["to"] = msg.To
+	}
+	if len(msg.Data) > 0 {
+		// Older nodes only read data, newer ones prefer input
+		arg["data"] = hexutil.Bytes(msg.Data)
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	return arg
+}
@@ -0,0 +1,42 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VerifyBytecode reports whether the runtime bytecode deployed at an address
+// hashes (keccak256) to expectedHash. ErrNoCode is returned for addresses
+// without code, such as externally owned accounts.
+func (c *Client) VerifyBytecode(ctx context.Context, address string, expectedHash common.Hash) (_ bool, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return false, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	account := common.HexToAddress(address)
+	var code []byte
+	err = c.retry(ctx, func() (err error) {
+		code, err = c.Client.CodeAt(ctx, account, nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get code: %w", err)
+	}
+	if len(code) == 0 {
+		return false, ErrNoCode
+	}
+
+	return crypto.Keccak256Hash(code) == expectedHash, nil
+}
+
+// ComputeCreate2Address returns the address a CREATE2 deployment by deployer
+// with the given salt and init code hash ends up at:
+// keccak256(0xff ++ deployer ++ salt ++ initCodeHash)[12:]. It makes no RPC call.
+func (c *Client) ComputeCreate2Address(deployer common.Address, salt common.Hash, initCodeHash common.Hash) common.Address {
+	return crypto.CreateAddress2(deployer, salt, initCodeHash.Bytes())
+}
@@ -0,0 +1,82 @@
+package ethereum
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+)
+
+// cacheMetrics exposes cache hit/miss counters per cache name
+var cacheMetrics = expvar.NewMap("ethereum_cache")
+
+// lruCache is a fixed-size, thread-safe least-recently-used cache
+type lruCache[K comparable, V any] struct {
+	name  string
+	size  int
+	items map[K]*list.Element
+	order *list.List
+	mu    sync.Mutex
+}
+
+// lruEntry is a key/value pair stored in the cache's list
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// newLRUCache creates a new LRU cache holding at most size entries.
+// A size of zero or less disables caching.
+func newLRUCache[K comparable, V any](name string, size int) *lruCache[K, V] {
+	return &lruCache[K, V]{
+		name:  name,
+		size:  size,
+		items: make(map[K]*list.Element),
+		order: list.New(),
+	}
+}
+
+// Get returns the cached value for key, marking it as recently used
+func (c *lruCache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c.size <= 0 {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		cacheMetrics.Add(c.name+"_misses", 1)
+		return zero, false
+	}
+
+	cacheMetrics.Add(c.name+"_hits", 1)
+	c.order.MoveToFront(elem)
+	return elem.Value.(*lruEntry[K, V]).value, true
+}
+
+// Add stores a value, evicting the least recently used entry if full
+func (c *lruCache[K, V]) Add(key K, value V) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		elem.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry[K, V]).key)
+		cacheMetrics.Add(c.name+"_evictions", 1)
+	}
+}
@@ -0,0 +1,61 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// CallMethod calls a read-only contract method with eth_call and returns its
+// decoded outputs. The call is made from the given address, or from the
+// client's own address when from is nil, as views may depend on msg.sender.
+func (c *Client) CallMethod(ctx context.Context, contractAddr, abiJSON, method string, from *common.Address, args ...interface{}) (_ []interface{}, err error) {
+	parsed, err := parseABI(abiJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := packMethodCall(parsed, method, args)
+	if err != nil {
+		return nil, err
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	sender := c.fromAddress
+	if from != nil {
+		sender = *from
+	}
+	contract := common.HexToAddress(contractAddr)
+
+	var output []byte
+	err = c.retry(ctx, func() (err error) {
+		output, err = c.Client.CallContract(ctx, ethereum.CallMsg{
+			From: sender,
+			To:   &contract,
+			Data: data,
+		}, nil)
+		return err
+	})
+	if err != nil {
+		if revertErr := asRevertError(err); revertErr != nil {
+			return nil, revertErr
+		}
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+
+	values, err := parsed.Unpack(method, output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack call result: %w", err)
+	}
+	for i, value := range values {
+		values[i] = jsonABIValue(value)
+	}
+	return values, nil
+}
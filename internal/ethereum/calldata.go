@@ -0,0 +1,191 @@
+package ethereum
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrUnknownSelector is returned when calldata's selector matches no known method
+var ErrUnknownSelector = errors.New("unknown method selector")
+
+// knownSignatures are common method signatures (ERC20, ERC721, ERC1155, WETH,
+// Uniswap V2 routers) used to decode calldata without an ABI
+var knownSignatures = []string{
+	// ERC20
+	"transfer(address,uint256)",
+	"transferFrom(address,address,uint256)",
+	"approve(address,uint256)",
+	"balanceOf(address)",
+	"allowance(address,address)",
+	"totalSupply()",
+	"name()",
+	"symbol()",
+	"decimals()",
+	"increaseAllowance(address,uint256)",
+	"decreaseAllowance(address,uint256)",
+	"permit(address,address,uint256,uint256,uint8,bytes32,bytes32)",
+	"mint(address,uint256)",
+	"burn(uint256)",
+	// ERC721
+	"ownerOf(uint256)",
+	"getApproved(uint256)",
+	"setApprovalForAll(address,bool)",
+	"isApprovedForAll(address,address)",
+	"safeTransferFrom(address,address,uint256)",
+	"safeTransferFrom(address,address,uint256,bytes)",
+	"tokenURI(uint256)",
+	// ERC1155
+	"safeTransferFrom(address,address,uint256,uint256,bytes)",
+	"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+	"balanceOfBatch(address[],uint256[])",
+	// WETH
+	"deposit()",
+	"withdraw(uint256)",
+	// Uniswap V2 routers
+	"swapExactTokensForTokens(uint256,uint256,address[],address,uint256)",
+	"swapTokensForExactTokens(uint256,uint256,address[],address,uint256)",
+	"swapExactETHForTokens(uint256,address[],address,uint256)",
+	"swapExactTokensForETH(uint256,uint256,address[],address,uint256)",
+	"addLiquidity(address,address,uint256,uint256,uint256,uint256,address,uint256)",
+	"removeLiquidity(address,address,uint256,uint256,uint256,address,uint256)",
+	// Multicall
+	"multicall(bytes[])",
+	"aggregate((address,bytes)[])",
+}
+
+// knownMethods indexes the known signatures by selector
+var knownMethods = func() map[[4]byte]abi.Method {
+	methods := make(map[[4]byte]abi.Method, len(knownSignatures))
+	for _, signature := range knownSignatures {
+		method, err := methodFromSignature(signature)
+		if err != nil {
+			// Signatures with types this parser doesn't handle are left out
+			continue
+		}
+		methods[[4]byte(method.ID)] = method
+	}
+	return methods
+}()
+
+// DecodedCall is calldata decoded into a method call
+type DecodedCall struct {
+	Method    string                 `json:"method"`
+	Signature string                 `json:"signature"`
+	Selector  string                 `json:"selector"`
+	Args      map[string]interface{} `json:"args"`
+}
+
+// DecodeCalldata decodes transaction input into the called method and its
+// arguments. With an ABI, the method is looked up in it; without one, the
+// selector is matched against a set of common method signatures.
+func DecodeCalldata(input []byte, abiJSON string) (*DecodedCall, error) {
+	if len(input) < 4 {
+		return nil, fmt.Errorf("input too short for a method selector")
+	}
+	selector := [4]byte(input[:4])
+
+	var method abi.Method
+	if abiJSON != "" {
+		parsed, err := parseABI(abiJSON)
+		if err != nil {
+			return nil, err
+		}
+		m, err := parsed.MethodById(selector[:])
+		if err != nil {
+			return nil, fmt.Errorf("%w %s", ErrUnknownSelector, hexutil.Encode(selector[:]))
+		}
+		method = *m
+	} else {
+		m, ok := knownMethods[selector]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnknownSelector, hexutil.Encode(selector[:]))
+		}
+		method = m
+	}
+
+	values, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode arguments of %s: %w", method.Sig, err)
+	}
+
+	args := make(map[string]interface{}, len(values))
+	for i, input := range method.Inputs {
+		name := input.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args[name] = jsonABIValue(values[i])
+	}
+
+	return &DecodedCall{
+		Method:    method.RawName,
+		Signature: method.Sig,
+		Selector:  hexutil.Encode(selector[:]),
+		Args:      args,
+	}, nil
+}
+
+// methodFromSignature builds an ABI method from a signature such as
+// "transfer(address,uint256)". Tuple types are written in parentheses.
+func methodFromSignature(signature string) (abi.Method, error) {
+	open := strings.IndexByte(signature, '(')
+	if open <= 0 || !strings.HasSuffix(signature, ")") {
+		return abi.Method{}, fmt.Errorf("invalid signature %q", signature)
+	}
+	name := signature[:open]
+
+	var inputs abi.Arguments
+	for _, typeName := range splitTypes(signature[open+1 : len(signature)-1]) {
+		typ, err := abiTypeFromSignature(typeName)
+		if err != nil {
+			return abi.Method{}, err
+		}
+		inputs = append(inputs, abi.Argument{Type: typ})
+	}
+	return abi.NewMethod(name, name, abi.Function, "nonpayable", false, false, inputs, nil), nil
+}
+
+// abiTypeFromSignature parses a type of a signature, including tuples such as "(address,bytes)[]"
+func abiTypeFromSignature(typeName string) (abi.Type, error) {
+	if !strings.HasPrefix(typeName, "(") {
+		return abi.NewType(typeName, "", nil)
+	}
+
+	closing := strings.LastIndexByte(typeName, ')')
+	var components []abi.ArgumentMarshaling
+	for i, component := range splitTypes(typeName[1:closing]) {
+		if strings.HasPrefix(component, "(") {
+			return abi.Type{}, fmt.Errorf("nested tuples are not supported: %s", typeName)
+		}
+		components = append(components, abi.ArgumentMarshaling{Name: fmt.Sprintf("field%d", i), Type: component})
+	}
+	return abi.NewType("tuple"+typeName[closing+1:], "", components)
+}
+
+// splitTypes splits a comma-separated list of types, keeping tuples together
+func splitTypes(list string) []string {
+	if list == "" {
+		return nil
+	}
+
+	var types []string
+	depth, start := 0, 0
+	for i, ch := range list {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(types, list[start:])
+}
@@ -0,0 +1,254 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrOffchainLookup is returned when a CCIP-read offchain lookup fails
+var ErrOffchainLookup = errors.New("offchain lookup failed")
+
+// maxOffchainLookups caps the CCIP-read round trips of a single call
+const maxOffchainLookups = 4
+
+// maxGatewayResponse is the largest gateway response body accepted
+const maxGatewayResponse = 1 << 20
+
+// maxGatewayRedirects caps the HTTP redirects followed by a gateway request
+const maxGatewayRedirects = 5
+
+// errGatewayAddress is returned when a gateway resolves to a non-public address
+var errGatewayAddress = errors.New("gateway address is not public")
+
+// ccipHTTPClient performs CCIP-read gateway requests. Gateway URLs are chosen
+// by whoever deployed the resolver, so only HTTPS requests to public
+// addresses are made, checked on every connection and redirect.
+var ccipHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil, // A proxy would connect to the gateway on our behalf, unchecked
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				addrPort, err := netip.ParseAddrPort(address)
+				if err != nil {
+					return err
+				}
+				if !isPublicAddr(addrPort.Addr()) {
+					return fmt.Errorf("%w: %s", errGatewayAddress, addrPort.Addr())
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxGatewayRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxGatewayRedirects)
+		}
+		if req.URL.Scheme != "https" {
+			return fmt.Errorf("redirect to non-https URL %s", req.URL.Redacted())
+		}
+		return nil
+	},
+}
+
+// isPublicAddr reports whether an IP address is publicly routable
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598)
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// offchainLookupError is the EIP-3668 OffchainLookup custom error
+var offchainLookupError = mustParseABI(`[
+	{"type":"error","name":"OffchainLookup","inputs":[
+		{"name":"sender","type":"address"},
+		{"name":"urls","type":"string[]"},
+		{"name":"callData","type":"bytes"},
+		{"name":"callbackFunction","type":"bytes4"},
+		{"name":"extraData","type":"bytes"}
+	]}
+]`).Errors["OffchainLookup"]
+
+// callbackArgs encodes the (bytes response, bytes extraData) arguments of a CCIP-read callback
+var callbackArgs = abi.Arguments{
+	{Type: mustNewType("bytes")},
+	{Type: mustNewType("bytes")},
+}
+
+// offchainLookup holds the fields of an OffchainLookup revert
+type offchainLookup struct {
+	Sender           common.Address
+	URLs             []string
+	CallData         []byte
+	CallbackFunction [4]byte
+	ExtraData        []byte
+}
+
+// callWithOffchainLookup executes an eth_call and follows EIP-3668 CCIP-read:
+// when the contract reverts with OffchainLookup, the data is fetched from one
+// of its gateways and passed to the callback function, up to maxOffchainLookups times
+func (c *Client) callWithOffchainLookup(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	for lookups := 0; ; lookups++ {
+		output, err := c.ensCall(ctx, to, data)
+		if err == nil {
+			return output, nil
+		}
+
+		var revertErr *RevertError
+		if !errors.As(err, &revertErr) {
+			return nil, fmt.Errorf("failed to call resolver: %w", err)
+		}
+		lookup, ok := parseOffchainLookup(revertErr.Data)
+		if !ok && lookups > 0 {
+			// The callback rejected the gateway's response
+			return nil, fmt.Errorf("%w: callback reverted: %w", ErrOffchainLookup, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("failed to call resolver: %w", err)
+		}
+		if !c.config.CCIPRead {
+			return nil, fmt.Errorf("%w: offchain resolvers are disabled (ethereum.ccipRead)", ErrOffchainLookup)
+		}
+		if lookups == maxOffchainLookups {
+			return nil, fmt.Errorf("%w: exceeded %d redirects", ErrOffchainLookup, maxOffchainLookups)
+		}
+		// Only the contract that was called may ask for a lookup, and it must name its callback
+		if lookup.Sender != to {
+			return nil, fmt.Errorf("%w: sender %s does not match resolver %s", ErrOffchainLookup, lookup.Sender.Hex(), to.Hex())
+		}
+		if lookup.CallbackFunction == ([4]byte{}) {
+			return nil, fmt.Errorf("%w: no callback function", ErrOffchainLookup)
+		}
+
+		response, err := queryGateways(ctx, lookup)
+		if err != nil {
+			return nil, err
+		}
+
+		args, err := callbackArgs.Pack(response, lookup.ExtraData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode offchain lookup callback: %w", err)
+		}
+		data = append(lookup.CallbackFunction[:], args...)
+	}
+}
+
+// parseOffchainLookup decodes an OffchainLookup revert, reporting whether the revert is one
+func parseOffchainLookup(data []byte) (*offchainLookup, bool) {
+	if len(data) < 4 || !bytes.Equal(data[:4], offchainLookupError.ID[:4]) {
+		return nil, false
+	}
+
+	values, err := offchainLookupError.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, false
+	}
+	return &offchainLookup{
+		Sender:           values[0].(common.Address),
+		URLs:             values[1].([]string),
+		CallData:         values[2].([]byte),
+		CallbackFunction: values[3].([4]byte),
+		ExtraData:        values[4].([]byte),
+	}, true
+}
+
+// queryGateways fetches the offchain data from the lookup's gateways in order,
+// returning the first successful response. Per EIP-3668, a 4xx response ends
+// the lookup while other failures move on to the next gateway.
+func queryGateways(ctx context.Context, lookup *offchainLookup) ([]byte, error) {
+	if len(lookup.URLs) == 0 {
+		return nil, fmt.Errorf("%w: no gateway URLs", ErrOffchainLookup)
+	}
+
+	var lastErr error
+	for _, url := range lookup.URLs {
+		response, status, err := queryGateway(ctx, url, lookup)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+		if status >= 400 && status < 500 {
+			break
+		}
+	}
+	return nil, fmt.Errorf("%w: %w", ErrOffchainLookup, lastErr)
+}
+
+// queryGateway performs one gateway request: a GET when the URL template
+// contains {data}, otherwise a POST of the sender and call data
+func queryGateway(ctx context.Context, urlTemplate string, lookup *offchainLookup) (_ []byte, status int, _ error) {
+	sender := strings.ToLower(lookup.Sender.Hex())
+	callData := hexutil.Encode(lookup.CallData)
+	url := strings.ReplaceAll(urlTemplate, "{sender}", sender)
+
+	var req *http.Request
+	var err error
+	if strings.Contains(url, "{data}") {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, strings.ReplaceAll(url, "{data}", callData), nil)
+	} else {
+		body, _ := json.Marshal(map[string]string{"sender": sender, "data": callData})
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid gateway URL %q: %w", urlTemplate, err)
+	}
+	if req.URL.Scheme != "https" {
+		return nil, 0, fmt.Errorf("gateway URL %q is not https", urlTemplate)
+	}
+
+	resp, err := ccipHTTPClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("gateway %s: %v", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode, fmt.Errorf("gateway %s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+
+	var result struct {
+		Data string `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxGatewayResponse)).Decode(&result); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("gateway %s returned an invalid response: %w", req.URL.Host, err)
+	}
+	data, err := hexutil.Decode(result.Data)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("gateway %s returned invalid data: %w", req.URL.Host, err)
+	}
+	return data, resp.StatusCode, nil
+}
+
+// mustNewType creates an ABI type known to be valid
+func mustNewType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
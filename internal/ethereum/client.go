@@ -2,23 +2,54 @@ package ethereum
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"errors"
+	"expvar"
 	"fmt"
 	"math/big"
+	"sort"
+	"strings"
+	"sync/atomic"
 
 	"github.com/em/go-web3/internal/config"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // Client wraps the Ethereum client with additional functionality
 type Client struct {
 	*ethclient.Client
 	config      *config.EthereumConfig
-	privateKey  *ecdsa.PrivateKey
+	signer      Signer
 	fromAddress common.Address
+
+	// Caches for immutable chain data
+	blockCache   *lruCache[uint64, *types.Block]
+	txCache      *lruCache[common.Hash, *types.Transaction]
+	receiptCache *lruCache[common.Hash, *types.Receipt]
+	timeCache    *lruCache[uint64, uint64] // Block timestamps by number
+	head         atomic.Uint64
+
+	// Short-circuits calls while the node is failing
+	breaker *circuitBreaker
+
+	// Retries read calls failing with transient errors
+	retryPolicy retryPolicy
+
+	// Tracing API detected by DetectTracing
+	traceAPI atomic.Value
+
+	// Cleared while waiting for a dropped provider connection to come back
+	connected atomic.Bool
+
+	// Nonces of sent transactions
+	nonces nonceManager
+
+	// Bounds the RPC calls in flight to the node
+	limiter *rpcLimiter
 }
 
 // NewClient creates a new Ethereum client
@@ -28,113 +59,435 @@ func NewClient(cfg *config.EthereumConfig) (*Client, error) {
 		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
 	}
 
-	privateKey, err := crypto.HexToECDSA(cfg.PrivateKey)
+	signer, err := newSigner(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("invalid private key: %w", err)
+		return nil, err
 	}
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("error casting public key to ECDSA")
+	c := &Client{
+		Client:       client,
+		config:       cfg,
+		signer:       signer,
+		fromAddress:  signer.Address(),
+		blockCache:   newLRUCache[uint64, *types.Block]("block", cfg.CacheSize),
+		txCache:      newLRUCache[common.Hash, *types.Transaction]("transaction", cfg.CacheSize),
+		receiptCache: newLRUCache[common.Hash, *types.Receipt]("receipt", cfg.CacheSize),
+		timeCache:    newLRUCache[uint64, uint64]("block_time", cfg.CacheSize),
+		breaker:      newCircuitBreaker(cfg.BreakerThreshold, cfg.BreakerCooldown),
+		limiter:      newRPCLimiter(cfg.MaxConcurrentCalls),
+		retryPolicy: retryPolicy{
+			attempts: cfg.RetryAttempts,
+			initial:  cfg.RetryInitialBackoff,
+			max:      cfg.RetryMaxBackoff,
+		},
 	}
+	c.connected.Store(true)
+	connectionMetrics.Set("state", expvar.Func(func() interface{} {
+		return c.ConnectionState()
+	}))
 
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	return c, nil
+}
 
-	return &Client{
-		Client:      client,
-		config:      cfg,
-		privateKey:  privateKey,
-		fromAddress: fromAddress,
-	}, nil
+// newSigner creates the signer configured for the client: an external Clef
+// instance when a Clef URL is set, otherwise the local private key
+func newSigner(cfg *config.EthereumConfig) (Signer, error) {
+	if cfg.ClefURL == "" {
+		return NewLocalSigner(cfg.PrivateKey)
+	}
+
+	if !common.IsHexAddress(cfg.ClefAccount) {
+		return nil, fmt.Errorf("a valid clefAccount is required when signing with Clef")
+	}
+	return NewClefSigner(cfg.ClefURL, common.HexToAddress(cfg.ClefAccount)), nil
+}
+
+// SignMessage signs an EIP-191 personal message with the client's account
+func (c *Client) SignMessage(message []byte) ([]byte, error) {
+	signature, err := c.signer.SignMessage(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+	return signature, nil
 }
 
 // GetBalance returns the balance of the given address
-func (c *Client) GetBalance(ctx context.Context, address string) (*big.Int, error) {
+func (c *Client) GetBalance(ctx context.Context, address string) (_ *big.Int, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
 	account := common.HexToAddress(address)
-	balance, err := c.Client.BalanceAt(ctx, account, nil)
+	var balance *big.Int
+	err = c.retry(ctx, func() (err error) {
+		balance, err = c.Client.BalanceAt(ctx, account, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance: %w", err)
+	}
+	return balance, nil
+}
+
+// GetBalanceAtBlock returns the balance of an address at the given block
+func (c *Client) GetBalanceAtBlock(ctx context.Context, address common.Address, blockNumber uint64) (_ *big.Int, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var balance *big.Int
+	err = c.retry(ctx, func() (err error) {
+		balance, err = c.Client.BalanceAt(ctx, address, new(big.Int).SetUint64(blockNumber))
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
 	return balance, nil
 }
 
-// SendTransaction sends a transaction to the given address with the specified amount
-func (c *Client) SendTransaction(ctx context.Context, to string, amount *big.Int) (string, error) {
+// ErrStateUnavailable is returned when the node no longer has the state of an
+// older block, as non-archive nodes only keep the state of recent blocks
+var ErrStateUnavailable = errors.New("historical state not available, an archive node is required")
+
+// ErrTooManyConfirmations is returned when more confirmations are requested than the chain has blocks
+var ErrTooManyConfirmations = errors.New("more confirmations than blocks in the chain")
+
+// GetConfirmedBalance returns the balance of an address as of the block that
+// has the given number of confirmations, i.e. head - confirmations, along with
+// that block number. Unlike the latest balance it can't be undone by a reorg
+// shallower than the confirmations.
+func (c *Client) GetConfirmedBalance(ctx context.Context, address string, confirmations uint64) (*big.Int, uint64, error) {
+	head, err := c.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	if confirmations > head {
+		return nil, 0, fmt.Errorf("%w: head is block %d", ErrTooManyConfirmations, head)
+	}
+
+	blockNumber := head - confirmations
+	balance, err := c.GetBalanceAtBlock(ctx, common.HexToAddress(address), blockNumber)
+	if err != nil {
+		if isStateUnavailable(err) {
+			return nil, 0, fmt.Errorf("%w: state of block %d: %v", ErrStateUnavailable, blockNumber, err)
+		}
+		return nil, 0, err
+	}
+	return balance, blockNumber, nil
+}
+
+// isStateUnavailable reports whether an error means the node pruned the state of the requested block
+func isStateUnavailable(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "missing trie node") ||
+		strings.Contains(msg, "historical state") ||
+		strings.Contains(msg, "state not available") ||
+		strings.Contains(msg, "state is not available") ||
+		strings.Contains(msg, "pruned") ||
+		strings.Contains(msg, "archive")
+}
+
+// ErrGasLimitTooLow is returned when an explicit gas limit is below the intrinsic transfer cost
+var ErrGasLimitTooLow = fmt.Errorf("gas limit below the intrinsic cost of %d", params.TxGas)
+
+// SendTransaction sends a transaction to the given address with the specified amount.
+// A gasLimit of 0 estimates the gas, which covers recipients with a receive() fallback.
+func (c *Client) SendTransaction(ctx context.Context, to string, amount *big.Int, gasLimit uint64) (string, error) {
 	toAddress := common.HexToAddress(to)
+	return c.SendTransactionWithOptions(ctx, TxOptions{
+		To:       &toAddress,
+		Value:    amount,
+		GasLimit: gasLimit,
+	})
+}
+
+// ExecuteContract calls a state-changing contract method in a signed transaction
+func (c *Client) ExecuteContract(ctx context.Context, contractAddr, abiJSON, method string, value *big.Int, args ...interface{}) (string, error) {
+	opts, err := contractCallOptions(contractAddr, abiJSON, method, value, args)
+	if err != nil {
+		return "", err
+	}
+	return c.SendTransactionWithOptions(ctx, opts)
+}
+
+// SubmitContractCall calls a contract method like ExecuteContract and also
+// reports the gas limit the transaction was sent with
+func (c *Client) SubmitContractCall(ctx context.Context, contractAddr, abiJSON, method string, value *big.Int, args ...interface{}) (*SentTransaction, error) {
+	opts, err := contractCallOptions(contractAddr, abiJSON, method, value, args)
+	if err != nil {
+		return nil, err
+	}
+	return c.SubmitTransaction(ctx, opts)
+}
 
-	// Get the nonce for the sender account
-	nonce, err := c.Client.PendingNonceAt(ctx, c.fromAddress)
+// contractCallOptions describes a transaction calling a contract method
+func contractCallOptions(contractAddr, abiJSON, method string, value *big.Int, args []interface{}) (TxOptions, error) {
+	contract := common.HexToAddress(contractAddr)
+
+	parsed, err := parseABI(abiJSON)
 	if err != nil {
-		return "", fmt.Errorf("failed to get nonce: %w", err)
+		return TxOptions{}, err
 	}
 
-	// Get suggested gas price
-	gasPrice, err := c.Client.SuggestGasPrice(ctx)
+	data, err := packMethodCall(parsed, method, args)
 	if err != nil {
-		return "", fmt.Errorf("failed to suggest gas price: %w", err)
+		return TxOptions{}, err
 	}
 
-	// Create transaction
-	tx := types.NewTransaction(
-		nonce,
-		toAddress,
-		amount,
-		21000, // Gas limit for a standard ETH transfer
-		gasPrice,
-		nil, // Data
-	)
+	return TxOptions{
+		To:    &contract,
+		Value: value,
+		Data:  data,
+	}, nil
+}
+
+// BroadcastError is returned when submitting a signed transaction failed
+// without the node rejecting it, like on a timeout or a dropped connection.
+// The node may have received the transaction, so it must not be sent again
+// with a new nonce.
+type BroadcastError struct {
+	Tx  *types.Transaction // The signed transaction that may have been sent
+	Err error
+}
+
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("transaction %s may have been sent: %v", e.Tx.Hash().Hex(), e.Err)
+}
 
+func (e *BroadcastError) Unwrap() error {
+	return e.Err
+}
+
+// signAndSend signs a transaction with the client's key and submits it.
+// Failures that leave open whether the node received it return a BroadcastError.
+func (c *Client) signAndSend(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
 	// Sign the transaction
 	chainID := big.NewInt(c.config.ChainID)
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), c.privateKey)
+	signedTx, err := c.signer.SignTx(tx, chainID)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign transaction: %w", err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
 
 	// Send the transaction
-	err = c.Client.SendTransaction(ctx, signedTx)
+	var submitted bool
+	err = c.limit(ctx, func() error {
+		submitted = true
+		return c.Client.SendTransaction(ctx, signedTx)
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to send transaction: %w", err)
+		err = fmt.Errorf("failed to send transaction: %w", err)
+		// Only an error response from the node means it didn't take the transaction
+		var rpcErr rpc.Error
+		if submitted && !errors.As(err, &rpcErr) {
+			return nil, &BroadcastError{Tx: signedTx, Err: err}
+		}
+		return nil, err
 	}
 
-	return signedTx.Hash().Hex(), nil
+	return signedTx, nil
 }
 
 // GetTransactionReceipt gets the receipt of a transaction
-func (c *Client) GetTransactionReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
+func (c *Client) GetTransactionReceipt(ctx context.Context, txHash string) (_ *types.Receipt, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
 	hash := common.HexToHash(txHash)
-	receipt, err := c.Client.TransactionReceipt(ctx, hash)
+	if receipt, ok := c.receiptCache.Get(hash); ok {
+		return receipt, nil
+	}
+
+	var receipt *types.Receipt
+	err = c.retry(ctx, func() (err error) {
+		receipt, err = c.Client.TransactionReceipt(ctx, hash)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
 	}
+
+	// Receipts of recent blocks are dropped if a reorg replaces the block
+	if receipt.BlockNumber != nil && c.isReorgSafe(ctx, receipt.BlockNumber.Uint64()) {
+		c.receiptCache.Add(hash, receipt)
+	}
 	return receipt, nil
 }
 
+// GetConfirmations returns the number of confirmations of a mined transaction,
+// counting its own block as the first
+func (c *Client) GetConfirmations(ctx context.Context, txHash string) (uint64, error) {
+	receipt, err := c.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return 0, err
+	}
+	head, err := c.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	mined := receipt.BlockNumber.Uint64()
+	if head < mined {
+		// The node serving the head may lag behind the one serving the receipt
+		return 1, nil
+	}
+	return head - mined + 1, nil
+}
+
 // GetTransactionByHash gets a transaction by its hash
-func (c *Client) GetTransactionByHash(ctx context.Context, txHash string) (*types.Transaction, bool, error) {
+func (c *Client) GetTransactionByHash(ctx context.Context, txHash string) (_ *types.Transaction, _ bool, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
 	hash := common.HexToHash(txHash)
-	tx, isPending, err := c.Client.TransactionByHash(ctx, hash)
+	if tx, ok := c.txCache.Get(hash); ok {
+		return tx, false, nil
+	}
+
+	var tx *types.Transaction
+	var isPending bool
+	err = c.retry(ctx, func() (err error) {
+		tx, isPending, err = c.Client.TransactionByHash(ctx, hash)
+		return err
+	})
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to get transaction: %w", err)
 	}
+
+	// Pending transactions may still be replaced or dropped
+	if !isPending {
+		c.txCache.Add(hash, tx)
+	}
 	return tx, isPending, nil
 }
 
+// ErrTxIndexOutOfRange is returned when a block has no transaction at the requested index
+var ErrTxIndexOutOfRange = errors.New("transaction index out of range")
+
+// GetTransactionInBlock gets the transaction at an index of a block
+func (c *Client) GetTransactionInBlock(ctx context.Context, blockHash common.Hash, index uint) (_ *types.Transaction, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var tx *types.Transaction
+	err = c.retry(ctx, func() (err error) {
+		tx, err = c.Client.TransactionInBlock(ctx, blockHash, index)
+		return err
+	})
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, fmt.Errorf("%w: block %s has no transaction %d", ErrTxIndexOutOfRange, blockHash.Hex(), index)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	c.txCache.Add(tx.Hash(), tx)
+	return tx, nil
+}
+
 // GetLatestBlockNumber gets the latest block number
-func (c *Client) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
-	blockNumber, err := c.Client.BlockNumber(ctx)
+func (c *Client) GetLatestBlockNumber(ctx context.Context) (_ uint64, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var blockNumber uint64
+	err = c.retry(ctx, func() (err error) {
+		blockNumber, err = c.Client.BlockNumber(ctx)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get latest block number: %w", err)
 	}
+	c.head.Store(blockNumber)
 	return blockNumber, nil
 }
 
 // GetBlockByNumber gets a block by its number
-func (c *Client) GetBlockByNumber(ctx context.Context, blockNumber uint64) (*types.Block, error) {
-	block, err := c.Client.BlockByNumber(ctx, big.NewInt(int64(blockNumber)))
+func (c *Client) GetBlockByNumber(ctx context.Context, blockNumber uint64) (_ *types.Block, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	if block, ok := c.blockCache.Get(blockNumber); ok {
+		return block, nil
+	}
+
+	var block *types.Block
+	err = c.retry(ctx, func() (err error) {
+		block, err = c.Client.BlockByNumber(ctx, big.NewInt(int64(blockNumber)))
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get block: %w", err)
 	}
+
+	if c.isReorgSafe(ctx, blockNumber) {
+		c.blockCache.Add(blockNumber, block)
+	}
 	return block, nil
 }
+
+// GetBlockLogs returns every log emitted in a block, ordered by log index.
+// The block is selected by hash, so the logs can't come from a block that
+// replaced it in a reorg.
+func (c *Client) GetBlockLogs(ctx context.Context, blockHash common.Hash) (_ []types.Log, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var logs []types.Log
+	err = c.retry(ctx, func() (err error) {
+		logs, err = c.Client.FilterLogs(ctx, ethereum.FilterQuery{
+			BlockHash: &blockHash,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block logs: %w", err)
+	}
+
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].Index < logs[j].Index
+	})
+	return logs, nil
+}
+
+// reorgSafeDepth is how far below the chain head a block must be before data
+// from it is cached. Blocks nearer the head can still be replaced in a reorg;
+// 64 blocks is two beacon chain epochs, by which a block is normally finalized.
+const reorgSafeDepth = 64
+
+// isReorgSafe reports whether a block is at least reorgSafeDepth below the
+// chain head, so that data from it can be cached.
+func (c *Client) isReorgSafe(ctx context.Context, blockNumber uint64) bool {
+	head := c.head.Load()
+	if blockNumber+reorgSafeDepth > head {
+		// The known head may be stale, refresh it
+		latest, err := c.GetLatestBlockNumber(ctx)
+		if err != nil {
+			return false
+		}
+		head = latest
+	}
+	return blockNumber+reorgSafeDepth <= head
+}
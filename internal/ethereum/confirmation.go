@@ -0,0 +1,101 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// inclusionPercentile is the percentile of a block's priority fees taken as
+// the lowest that made it into the block
+const inclusionPercentile = 10
+
+// defaultBlockTime is assumed when too few blocks exist to measure the block time
+const defaultBlockTime = 12 * time.Second
+
+var (
+	// ErrGasPriceBelowBaseFee is returned when a gas price can't pay the next block's base fee
+	ErrGasPriceBelowBaseFee = errors.New("gas price is below the next block's base fee")
+
+	// ErrConfirmationUnlikely is returned when a gas price would not have been
+	// included in any of the recent blocks
+	ErrConfirmationUnlikely = errors.New("gas price is too low to be included at recent fee levels")
+)
+
+// EstimateConfirmationTime estimates how long a transaction paying gasPrice
+// waits to be included, assuming recent fee levels persist. A transaction is
+// counted as fitting a recent block when gasPrice covers the block's base fee
+// plus the 10th percentile of its priority fees; with p the share of the
+// latest blocks it fits, it is expected after 1/p blocks of the average
+// recent block time.
+func (c *Client) EstimateConfirmationTime(ctx context.Context, gasPrice *big.Int) (_ time.Duration, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var head *types.Header
+	var history *ethereum.FeeHistory
+	err = c.retry(ctx, func() (err error) {
+		if head, err = c.Client.HeaderByNumber(ctx, nil); err != nil {
+			return err
+		}
+		history, err = c.Client.FeeHistory(ctx, strategyHistoryBlocks, head.Number, []float64{inclusionPercentile})
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get fee history: %w", err)
+	}
+
+	if head.BaseFee != nil && gasPrice.Cmp(nextBaseFee(head)) < 0 {
+		return 0, ErrGasPriceBelowBaseFee
+	}
+
+	var fits int
+	for i, rewards := range history.Reward {
+		if len(rewards) == 0 || i >= len(history.BaseFee) {
+			continue
+		}
+		if gasPrice.Cmp(new(big.Int).Add(history.BaseFee[i], rewards[0])) >= 0 {
+			fits++
+		}
+	}
+	if fits == 0 {
+		return 0, ErrConfirmationUnlikely
+	}
+
+	blockTime, err := c.averageBlockTime(ctx, head, history.OldestBlock)
+	if err != nil {
+		return 0, err
+	}
+	expectedBlocks := float64(len(history.Reward)) / float64(fits)
+	return time.Duration(expectedBlocks * float64(blockTime)), nil
+}
+
+// averageBlockTime returns the average time between blocks from oldest to head
+func (c *Client) averageBlockTime(ctx context.Context, head *types.Header, oldest *big.Int) (time.Duration, error) {
+	if oldest == nil || oldest.Cmp(head.Number) >= 0 {
+		return defaultBlockTime, nil
+	}
+
+	var first *types.Header
+	err := c.retry(ctx, func() (err error) {
+		first, err = c.Client.HeaderByNumber(ctx, oldest)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block %s: %w", oldest, err)
+	}
+
+	blocks := new(big.Int).Sub(head.Number, oldest).Int64()
+	if head.Time <= first.Time {
+		return defaultBlockTime, nil
+	}
+	return time.Duration(head.Time-first.Time) * time.Second / time.Duration(blocks), nil
+}
@@ -0,0 +1,81 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// NetworkCongestion returns the average ratio of gas used to gas limit over
+// the latest blocks, between 0 and 1. Above 0.5, blocks are fuller than their
+// target and the base fee is rising.
+func (c *Client) NetworkCongestion(ctx context.Context) (_ float64, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var head uint64
+	err = c.retry(ctx, func() (err error) {
+		head, err = c.Client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+
+	sample := min(uint64(max(c.config.CongestionSampleBlocks, 1)), head+1)
+
+	numbers := make([]uint64, sample)
+	for i := range numbers {
+		numbers[i] = head - uint64(i)
+	}
+	headers, err := c.headersByNumber(ctx, numbers)
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	var counted int
+	for _, header := range headers {
+		if header.GasLimit == 0 {
+			continue
+		}
+		total += float64(header.GasUsed) / float64(header.GasLimit)
+		counted++
+	}
+	if counted == 0 {
+		return 0, nil
+	}
+	return total / float64(counted), nil
+}
+
+// headersByNumber fetches several block headers in a single batched call
+func (c *Client) headersByNumber(ctx context.Context, numbers []uint64) ([]*types.Header, error) {
+	headers := make([]*types.Header, len(numbers))
+	reqs := make([]rpc.BatchElem, len(numbers))
+	for i, number := range numbers {
+		reqs[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(number), false},
+			Result: &headers[i],
+		}
+	}
+
+	if err := c.retry(ctx, func() error { return c.Client.Client().BatchCallContext(ctx, reqs) }); err != nil {
+		return nil, fmt.Errorf("failed to batch fetch headers: %w", err)
+	}
+	for i, req := range reqs {
+		if req.Error != nil {
+			return nil, fmt.Errorf("failed to get block %d: %w", numbers[i], req.Error)
+		}
+		if headers[i] == nil {
+			return nil, fmt.Errorf("block %d not found", numbers[i])
+		}
+	}
+	return headers, nil
+}
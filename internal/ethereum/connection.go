@@ -0,0 +1,69 @@
+package ethereum
+
+import (
+	"context"
+	"expvar"
+	"time"
+)
+
+// Connection states reported by ConnectionState
+const (
+	ConnectionConnected    = "connected"
+	ConnectionReconnecting = "reconnecting"
+)
+
+// Delays between reconnection probes, doubled after each failed probe
+const (
+	reconnectInitialBackoff = time.Second
+	reconnectMaxBackoff     = 30 * time.Second // Used when none is configured
+)
+
+// connectionMetrics exposes the provider connection state and reconnections
+var connectionMetrics = expvar.NewMap("ethereum_connection")
+
+// WaitForReconnect is called after a subscription was dropped with the node
+// connection. It blocks until the node answers again, probing with
+// exponential backoff capped at the configured maximum, or the context ends.
+// The RPC client redials the provider on the first call after a connection
+// loss, so each probe also attempts to re-establish the connection.
+func (c *Client) WaitForReconnect(ctx context.Context) error {
+	if c.connected.CompareAndSwap(true, false) {
+		connectionMetrics.Add("disconnects", 1)
+	}
+
+	maxDelay := c.config.ReconnectMaxBackoff
+	if maxDelay <= 0 {
+		maxDelay = reconnectMaxBackoff
+	}
+
+	delay := reconnectInitialBackoff
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := c.limit(probeCtx, func() error {
+			_, err := c.Client.BlockNumber(probeCtx)
+			return err
+		})
+		cancel()
+		if err == nil {
+			if c.connected.CompareAndSwap(false, true) {
+				connectionMetrics.Add("reconnects", 1)
+			}
+			return nil
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay = min(delay*2, maxDelay)
+	}
+}
+
+// ConnectionState returns whether the provider connection is up or being re-established
+func (c *Client) ConnectionState() string {
+	if c.connected.Load() {
+		return ConnectionConnected
+	}
+	return ConnectionReconnecting
+}
@@ -0,0 +1,207 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// identifierPattern matches Solidity identifiers
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// DecodedLog is a receipt log decoded against a contract ABI
+type DecodedLog struct {
+	Address  common.Address         `json:"address"`
+	LogIndex uint                   `json:"logIndex"`
+	Event    string                 `json:"event"`
+	Args     map[string]interface{} `json:"args"`
+}
+
+// DecodeReceiptLogs fetches a transaction's receipt and decodes its logs
+// against the ABI. Logs that don't match an event of the ABI are skipped.
+func (c *Client) DecodeReceiptLogs(ctx context.Context, txHash, abiJSON string) ([]DecodedLog, error) {
+	parsed, err := parseABI(abiJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := c.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := []DecodedLog{}
+	for _, vLog := range receipt.Logs {
+		event, ok := matchEvent(parsed, vLog)
+		if !ok {
+			continue
+		}
+
+		args, err := DecodeEventArgs(event, vLog)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode log %d: %w", vLog.Index, err)
+		}
+
+		decoded = append(decoded, DecodedLog{
+			Address:  vLog.Address,
+			LogIndex: vLog.Index,
+			Event:    event.Name,
+			Args:     args,
+		})
+	}
+	return decoded, nil
+}
+
+// matchEvent finds the ABI event of a log by topic0 and its number of indexed
+// topics, so that e.g. an ERC721 Transfer doesn't match the ERC20 one.
+// Anonymous events have no signature topic and are matched by their number of
+// indexed arguments, if exactly one of them fits.
+func matchEvent(parsed abi.ABI, vLog *types.Log) (abi.Event, bool) {
+	if len(vLog.Topics) > 0 {
+		if event, err := parsed.EventByID(vLog.Topics[0]); err == nil {
+			return *event, countIndexedArgs(event.Inputs) == len(vLog.Topics)-1
+		}
+	}
+
+	var match *abi.Event
+	for name := range parsed.Events {
+		event := parsed.Events[name]
+		if !event.Anonymous || countIndexedArgs(event.Inputs) != len(vLog.Topics) {
+			continue
+		}
+		if match != nil {
+			return abi.Event{}, false
+		}
+		match = &event
+	}
+	if match == nil {
+		return abi.Event{}, false
+	}
+	return *match, true
+}
+
+// EventTopic returns the topic of a canonical event signature such as
+// "Transfer(address,address,uint256)": no spaces, no parameter names and
+// full type names (uint256, not uint)
+func EventTopic(signature string) (common.Hash, error) {
+	method, err := methodFromSignature(signature)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !identifierPattern.MatchString(method.RawName) || method.Sig != signature {
+		return common.Hash{}, fmt.Errorf("non-canonical event signature %q", signature)
+	}
+	return crypto.Keccak256Hash([]byte(signature)), nil
+}
+
+// DecodeEventArgs decodes the indexed arguments of a log from its topics and the
+// others from the log data. Non-indexed dynamic types (string, bytes, arrays)
+// are decoded from their offsets in the data; indexed dynamic types are only
+// available as the keccak256 hash stored in the topic.
+func DecodeEventArgs(event abi.Event, vLog *types.Log) (map[string]interface{}, error) {
+	// Both unpackers key the map by argument name, so unnamed arguments get
+	// positional names to keep them from overwriting each other
+	inputs := make(abi.Arguments, len(event.Inputs))
+	for i, input := range event.Inputs {
+		if input.Name == "" {
+			input.Name = fmt.Sprintf("arg%d", i)
+		}
+		inputs[i] = input
+	}
+
+	var indexed abi.Arguments
+	for _, input := range inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	topics := vLog.Topics
+	if !event.Anonymous {
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("log has no topics for event %s", event.Name)
+		}
+		topics = topics[1:]
+	}
+	if len(topics) != len(indexed) {
+		return nil, fmt.Errorf("log has %d indexed topics, event %s expects %d", len(topics), event.Name, len(indexed))
+	}
+
+	args := make(map[string]interface{}, len(inputs))
+	if err := inputs.NonIndexed().UnpackIntoMap(args, vLog.Data); err != nil {
+		return nil, err
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, topics); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]interface{}, len(args))
+	for _, input := range inputs {
+		result[input.Name] = jsonABIValue(args[input.Name])
+	}
+	return result, nil
+}
+
+// countIndexedArgs returns the number of indexed arguments
+func countIndexedArgs(args abi.Arguments) int {
+	n := 0
+	for _, arg := range args {
+		if arg.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+// jsonABIValue converts decoded ABI values to JSON-friendly forms: integers
+// as decimal strings and byte arrays as hex
+func jsonABIValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case []byte:
+		return hexutil.Encode(v)
+	case common.Address, common.Hash, string, bool, nil:
+		return v
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(raw), rv)
+			return hexutil.Encode(raw)
+		}
+		fallthrough
+	case reflect.Slice:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = jsonABIValue(rv.Index(i).Interface())
+		}
+		return items
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(rv.Uint())
+	case reflect.Struct:
+		// Tuples decode into structs whose fields carry the component names as JSON tags
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			name := rv.Type().Field(i).Tag.Get("json")
+			if name == "" {
+				name = rv.Type().Field(i).Name
+			}
+			fields[name] = jsonABIValue(rv.Field(i).Interface())
+		}
+		return fields
+	}
+	return value
+}
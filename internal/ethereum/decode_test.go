@@ -0,0 +1,100 @@
+package ethereum
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mustParseTestABI parses an ABI for a test
+func mustParseTestABI(t *testing.T, abiJSON string) abi.ABI {
+	t.Helper()
+
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatalf("parse ABI: %v", err)
+	}
+	return parsed
+}
+
+func TestDecodeEventArgsIndexedString(t *testing.T) {
+	parsed := mustParseTestABI(t, `[{"type":"event","name":"Registered","inputs":[
+		{"name":"name","type":"string","indexed":true},
+		{"name":"owner","type":"address","indexed":false}]}]`)
+	event := parsed.Events["Registered"]
+
+	owner := common.HexToAddress("0x742d35Cc6634C0532925a3b844Bc454e4438f44e")
+	data, err := event.Inputs.NonIndexed().Pack(owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nameHash := crypto.Keccak256Hash([]byte("alice.eth"))
+	vLog := &types.Log{Topics: []common.Hash{event.ID, nameHash}, Data: data}
+
+	args, err := DecodeEventArgs(event, vLog)
+	if err != nil {
+		t.Fatalf("DecodeEventArgs: %v", err)
+	}
+	// Only the hash of an indexed string is in the log
+	if args["name"] != nameHash {
+		t.Errorf("name = %v, want %v", args["name"], nameHash)
+	}
+	if args["owner"] != owner {
+		t.Errorf("owner = %v, want %v", args["owner"], owner)
+	}
+}
+
+func TestDecodeEventArgsBytesArray(t *testing.T) {
+	parsed := mustParseTestABI(t, `[{"type":"event","name":"Batch","inputs":[
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"payloads","type":"bytes[]","indexed":false},
+		{"name":"count","type":"uint256","indexed":false}]}]`)
+	event := parsed.Events["Batch"]
+
+	payloads := [][]byte{{0x01, 0x02}, {}, {0xff}}
+	data, err := event.Inputs.NonIndexed().Pack(payloads, common.Big3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	vLog := &types.Log{Topics: []common.Hash{event.ID, common.BytesToHash(sender.Bytes())}, Data: data}
+
+	args, err := DecodeEventArgs(event, vLog)
+	if err != nil {
+		t.Fatalf("DecodeEventArgs: %v", err)
+	}
+	want := []interface{}{"0x0102", "0x", "0xff"}
+	if !reflect.DeepEqual(args["payloads"], want) {
+		t.Errorf("payloads = %v, want %v", args["payloads"], want)
+	}
+	if args["count"] != "3" {
+		t.Errorf("count = %v, want 3", args["count"])
+	}
+	if args["sender"] != sender {
+		t.Errorf("sender = %v, want %v", args["sender"], sender)
+	}
+}
+
+func TestMatchEventSkipsDifferentIndexedCount(t *testing.T) {
+	erc20 := mustParseTestABI(t, `[{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]}]`)
+	topic := erc20.Events["Transfer"].ID
+
+	// ERC721 Transfer: same signature, but the token id is indexed too
+	erc721Log := &types.Log{Topics: []common.Hash{topic, {}, {}, common.BigToHash(common.Big1)}}
+	if _, ok := matchEvent(erc20, erc721Log); ok {
+		t.Error("ERC721 Transfer matched the ERC20 Transfer event")
+	}
+
+	erc20Log := &types.Log{Topics: []common.Hash{topic, {}, {}}, Data: common.BigToHash(common.Big1).Bytes()}
+	if event, ok := matchEvent(erc20, erc20Log); !ok || event.Name != "Transfer" {
+		t.Errorf("ERC20 Transfer not matched: %v, %v", event.Name, ok)
+	}
+}
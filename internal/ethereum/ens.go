@@ -0,0 +1,430 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ensRegistry is the address of the ENS registry, the same on mainnet and the main testnets
+var ensRegistry = common.HexToAddress("0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e")
+
+// ErrNameNotFound is returned when an ENS name has no resolver or no address
+var ErrNameNotFound = errors.New("ENS name not found")
+
+// ENSStep identifies the step at which ENS resolution failed
+type ENSStep string
+
+// Steps at which ENS resolution can fail
+const (
+	ENSStepNoRegistryRecord ENSStep = "no_registry_record"     // The name and its ancestors are not in the registry
+	ENSStepNoResolver       ENSStep = "no_resolver"            // No resolver answers for the name
+	ENSStepZeroAddress      ENSStep = "zero_address"           // The resolver returned the zero address
+	ENSStepOffchainLookup   ENSStep = "offchain_lookup_failed" // The CCIP-read gateways failed
+	ENSStepNoName           ENSStep = "no_name"                // The reverse resolver returned an empty name
+	ENSStepNameMismatch     ENSStep = "name_mismatch"          // The reverse name doesn't resolve back to the address
+)
+
+// ENSError is returned when ENS resolution fails at a known step
+type ENSError struct {
+	Step ENSStep
+	Err  error
+}
+
+func (e *ENSError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ENSError) Unwrap() error {
+	return e.Err
+}
+
+// ENSResolution describes how a name or address was resolved, or the step at
+// which resolution failed. Addresses are checksummed hex.
+type ENSResolution struct {
+	Name       string  `json:"name,omitempty"`
+	Address    string  `json:"address,omitempty"`
+	Resolver   string  `json:"resolver,omitempty"`
+	Wildcard   bool    `json:"wildcard,omitempty"` // The resolver is set on an ancestor of the name (ENSIP-10)
+	FailedStep ENSStep `json:"failedStep,omitempty"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// ensABI holds the registry and resolver methods used for resolution
+var ensABI = mustParseABI(`[
+	{"type":"function","name":"resolver","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"type":"address"}]},
+	{"type":"function","name":"recordExists","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"type":"bool"}]},
+	{"type":"function","name":"supportsInterface","stateMutability":"view","inputs":[{"name":"interfaceID","type":"bytes4"}],"outputs":[{"type":"bool"}]},
+	{"type":"function","name":"addr","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"type":"address"}]},
+	{"type":"function","name":"name","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"type":"string"}]},
+	{"type":"function","name":"resolve","stateMutability":"view","inputs":[{"name":"name","type":"bytes"},{"name":"data","type":"bytes"}],"outputs":[{"type":"bytes"}]}
+]`)
+
+// extendedResolverInterface is the ENSIP-10 interface ID of resolve(bytes,bytes)
+var extendedResolverInterface = [4]byte{0x90, 0x61, 0xb9, 0x23}
+
+// ResolveName resolves an ENS name to an address. Wildcard resolvers (ENSIP-10)
+// and offchain resolvers using CCIP-read (EIP-3668) are supported. Failed
+// resolutions return an *ENSError.
+func (c *Client) ResolveName(ctx context.Context, name string) (_ common.Address, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return common.Address{}, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	name, err = normalizeENSName(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return c.resolveName(ctx, name, &ENSResolution{})
+}
+
+// ResolveNameDetails resolves an ENS name like ResolveName, reporting the
+// resolver used or, instead of an error, the step at which resolution failed
+func (c *Client) ResolveNameDetails(ctx context.Context, name string) (_ *ENSResolution, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	name, err = normalizeENSName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	resolution := &ENSResolution{Name: name}
+	address, err := c.resolveName(ctx, name, resolution)
+	if resolution.fail(err) {
+		return resolution, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	resolution.Address = address.Hex()
+	return resolution, nil
+}
+
+// LookupAddressDetails finds the primary ENS name of an address through its
+// reverse record, reporting the reverse resolver used or the step at which
+// the lookup failed. The name only counts if it resolves back to the address.
+func (c *Client) LookupAddressDetails(ctx context.Context, address common.Address) (_ *ENSResolution, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	resolution := &ENSResolution{Address: address.Hex()}
+	err = c.lookupAddress(ctx, address, resolution)
+	if resolution.fail(err) {
+		return resolution, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resolution, nil
+}
+
+// fail records the step of an *ENSError in the resolution, reporting whether err was one
+func (r *ENSResolution) fail(err error) bool {
+	var ensErr *ENSError
+	if !errors.As(err, &ensErr) {
+		return false
+	}
+	r.FailedStep = ensErr.Step
+	r.Error = ensErr.Error()
+	return true
+}
+
+// normalizeENSName lowercases a name and strips its trailing dot
+func normalizeENSName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	if name == "" {
+		return "", fmt.Errorf("empty ENS name")
+	}
+	return name, nil
+}
+
+// resolveName resolves a normalized name to an address, recording the
+// resolver it used in resolution
+func (c *Client) resolveName(ctx context.Context, name string, resolution *ENSResolution) (common.Address, error) {
+	node := namehash(name)
+
+	resolver, exact, err := c.findResolver(ctx, name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	resolution.Resolver = resolver.Hex()
+	resolution.Wildcard = !exact
+
+	addrCall, err := ensABI.Pack("addr", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	extended, err := c.supportsInterface(ctx, resolver, extendedResolverInterface)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	var output []byte
+	switch {
+	case extended:
+		// Wildcard and offchain resolvers wrap the record call in resolve()
+		dnsName, err := dnsEncode(name)
+		if err != nil {
+			return common.Address{}, err
+		}
+		resolveCall, err := ensABI.Pack("resolve", dnsName, addrCall)
+		if err != nil {
+			return common.Address{}, err
+		}
+		wrapped, err := c.resolverCall(ctx, resolver, resolveCall)
+		if err != nil {
+			return common.Address{}, err
+		}
+		values, err := ensABI.Unpack("resolve", wrapped)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("invalid resolve() result: %w", err)
+		}
+		output = values[0].([]byte)
+	case exact:
+		output, err = c.resolverCall(ctx, resolver, addrCall)
+		if err != nil {
+			return common.Address{}, err
+		}
+	default:
+		// A parent's resolver only answers for subnames through ENSIP-10
+		return common.Address{}, &ENSError{
+			Step: ENSStepNoResolver,
+			Err:  fmt.Errorf("%w: resolver %s of a parent name does not support wildcard resolution", ErrNameNotFound, resolver.Hex()),
+		}
+	}
+
+	values, err := ensABI.Unpack("addr", output)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid addr() result: %w", err)
+	}
+	address := values[0].(common.Address)
+	if address == (common.Address{}) {
+		return common.Address{}, &ENSError{
+			Step: ENSStepZeroAddress,
+			Err:  fmt.Errorf("%w: resolver %s returned the zero address", ErrNameNotFound, resolver.Hex()),
+		}
+	}
+	return address, nil
+}
+
+// lookupAddress finds the name in the reverse record of an address and
+// checks that it resolves back to the address
+func (c *Client) lookupAddress(ctx context.Context, address common.Address, resolution *ENSResolution) error {
+	reverseName := strings.ToLower(address.Hex()[2:]) + ".addr.reverse"
+	node := namehash(reverseName)
+
+	resolver, err := c.registryResolver(ctx, node)
+	if err != nil {
+		return err
+	}
+	if resolver == (common.Address{}) {
+		return c.missingResolverError(ctx, reverseName)
+	}
+	resolution.Resolver = resolver.Hex()
+
+	nameCall, err := ensABI.Pack("name", node)
+	if err != nil {
+		return err
+	}
+	output, err := c.resolverCall(ctx, resolver, nameCall)
+	if err != nil {
+		return err
+	}
+	values, err := ensABI.Unpack("name", output)
+	if err != nil {
+		return fmt.Errorf("invalid name() result: %w", err)
+	}
+	name := values[0].(string)
+	if name == "" {
+		return &ENSError{
+			Step: ENSStepNoName,
+			Err:  fmt.Errorf("%w: reverse resolver %s returned no name", ErrNameNotFound, resolver.Hex()),
+		}
+	}
+	resolution.Name = name
+
+	normalized, err := normalizeENSName(name)
+	if err != nil {
+		return err
+	}
+	forward, err := c.resolveName(ctx, normalized, &ENSResolution{})
+	var ensErr *ENSError
+	if err != nil && !errors.As(err, &ensErr) {
+		return err
+	}
+	if forward != address {
+		return &ENSError{
+			Step: ENSStepNameMismatch,
+			Err:  fmt.Errorf("%w: reverse name %s does not resolve back to %s", ErrNameNotFound, name, address.Hex()),
+		}
+	}
+	return nil
+}
+
+// findResolver returns the resolver of a name, or per ENSIP-10 of its closest
+// ancestor with one. exact reports whether the resolver is set on the name itself.
+func (c *Client) findResolver(ctx context.Context, name string) (resolver common.Address, exact bool, err error) {
+	for current := name; current != ""; {
+		resolver, err := c.registryResolver(ctx, namehash(current))
+		if err != nil {
+			return common.Address{}, false, err
+		}
+		if resolver != (common.Address{}) {
+			return resolver, current == name, nil
+		}
+
+		_, parent, found := strings.Cut(current, ".")
+		if !found {
+			break
+		}
+		current = parent
+	}
+	return common.Address{}, false, c.missingResolverError(ctx, name)
+}
+
+// registryResolver returns the resolver set on a node in the registry, or the zero address
+func (c *Client) registryResolver(ctx context.Context, node common.Hash) (common.Address, error) {
+	data, err := ensABI.Pack("resolver", node)
+	if err != nil {
+		return common.Address{}, err
+	}
+	output, err := c.ensCall(ctx, ensRegistry, data)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get ENS resolver: %w", err)
+	}
+	values, err := ensABI.Unpack("resolver", output)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("invalid resolver() result: %w", err)
+	}
+	return values[0].(common.Address), nil
+}
+
+// missingResolverError tells a name missing from the registry apart from a
+// registered name without a resolver
+func (c *Client) missingResolverError(ctx context.Context, name string) error {
+	data, err := ensABI.Pack("recordExists", namehash(name))
+	if err != nil {
+		return err
+	}
+	output, err := c.ensCall(ctx, ensRegistry, data)
+	if err != nil {
+		return fmt.Errorf("failed to check ENS registry record: %w", err)
+	}
+	values, err := ensABI.Unpack("recordExists", output)
+	if err != nil {
+		return fmt.Errorf("invalid recordExists() result: %w", err)
+	}
+
+	if values[0].(bool) {
+		return &ENSError{
+			Step: ENSStepNoResolver,
+			Err:  fmt.Errorf("%w: %s has no resolver", ErrNameNotFound, name),
+		}
+	}
+	return &ENSError{
+		Step: ENSStepNoRegistryRecord,
+		Err:  fmt.Errorf("%w: %s is not in the ENS registry", ErrNameNotFound, name),
+	}
+}
+
+// resolverCall calls a resolver, following CCIP-read offchain lookups
+func (c *Client) resolverCall(ctx context.Context, resolver common.Address, data []byte) ([]byte, error) {
+	output, err := c.callWithOffchainLookup(ctx, resolver, data)
+	if errors.Is(err, ErrOffchainLookup) {
+		return nil, &ENSError{Step: ENSStepOffchainLookup, Err: err}
+	}
+	return output, err
+}
+
+// supportsInterface reports whether a contract implements an ERC-165 interface.
+// Contracts without ERC-165 support are reported as not implementing it.
+func (c *Client) supportsInterface(ctx context.Context, contract common.Address, id [4]byte) (bool, error) {
+	data, err := ensABI.Pack("supportsInterface", id)
+	if err != nil {
+		return false, err
+	}
+	output, err := c.ensCall(ctx, contract, data)
+	if err != nil {
+		var revertErr *RevertError
+		if errors.As(err, &revertErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check resolver interface: %w", err)
+	}
+
+	values, err := ensABI.Unpack("supportsInterface", output)
+	if err != nil {
+		return false, nil
+	}
+	return values[0].(bool), nil
+}
+
+// ensCall executes an eth_call against the latest block, returning a
+// *RevertError if the call reverts
+func (c *Client) ensCall(ctx context.Context, to common.Address, data []byte) ([]byte, error) {
+	var output []byte
+	err := c.retry(ctx, func() (err error) {
+		output, err = c.Client.CallContract(ctx, ethereum.CallMsg{To: &to, Data: data}, nil)
+		return err
+	})
+	if err != nil {
+		if revertErr := asRevertError(err); revertErr != nil {
+			return nil, revertErr
+		}
+		return nil, err
+	}
+	return output, nil
+}
+
+// namehash computes the ENS namehash of a normalized name
+func namehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// dnsEncode encodes a name in DNS wire format, as used by ENSIP-10 resolve()
+func dnsEncode(name string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 255 {
+			return nil, fmt.Errorf("invalid ENS name %q", name)
+		}
+		buf.WriteByte(byte(len(label)))
+		buf.WriteString(label)
+	}
+	buf.WriteByte(0)
+	return buf.Bytes(), nil
+}
+
+// mustParseABI parses an ABI definition known to be valid
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
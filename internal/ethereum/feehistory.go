@@ -0,0 +1,161 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// FeeHistoryResult holds recent base fees and priority fee percentiles,
+// with amounts in wei as decimal strings
+type FeeHistoryResult struct {
+	OldestBlock   uint64     `json:"oldestBlock"`
+	BaseFees      []string   `json:"baseFees"`      // One per block, plus the next block's base fee
+	GasUsedRatios []float64  `json:"gasUsedRatios"` // One per block
+	Percentiles   []float64  `json:"percentiles"`
+	Rewards       [][]string `json:"rewards"` // Per block, the priority fee at each percentile
+}
+
+// FeeHistory returns the fee history of the latest blockCount blocks with
+// eth_feeHistory. rewardPercentiles must be ascending values between 0 and 100.
+func (c *Client) FeeHistory(ctx context.Context, blockCount uint64, rewardPercentiles []float64) (_ *FeeHistoryResult, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var history *ethereum.FeeHistory
+	err = c.retry(ctx, func() (err error) {
+		history, err = c.Client.FeeHistory(ctx, blockCount, nil, rewardPercentiles)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+
+	result := &FeeHistoryResult{
+		OldestBlock:   history.OldestBlock.Uint64(),
+		BaseFees:      bigIntStrings(history.BaseFee),
+		GasUsedRatios: history.GasUsedRatio,
+		Percentiles:   rewardPercentiles,
+		Rewards:       make([][]string, len(history.Reward)),
+	}
+	for i, rewards := range history.Reward {
+		result.Rewards[i] = bigIntStrings(rewards)
+	}
+	return result, nil
+}
+
+// bigIntStrings converts big integers to decimal strings
+func bigIntStrings(values []*big.Int) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = v.String()
+	}
+	return out
+}
+
+// londonRules enables the EIP-1559 base fee rules when computing the next base fee
+var londonRules = &params.ChainConfig{LondonBlock: common.Big0}
+
+// ErrNoBaseFee is returned for base fee queries on chains without EIP-1559
+var ErrNoBaseFee = errors.New("connected chain has no base fee")
+
+// FeeSuggestion holds the fees to pay for a transaction to be included soon,
+// in wei as decimal strings. The EIP-1559 fields are only set on chains with
+// a base fee.
+type FeeSuggestion struct {
+	GasPrice       string `json:"gasPrice"`
+	BaseFee        string `json:"baseFee,omitempty"`     // Of the latest block
+	NextBaseFee    string `json:"nextBaseFee,omitempty"` // Of the block being built
+	MaxPriorityFee string `json:"maxPriorityFee,omitempty"`
+	MaxFee         string `json:"maxFee,omitempty"`
+}
+
+// NextBaseFee returns the base fee of the next block, computed from the
+// latest header with the EIP-1559 formula
+func (c *Client) NextBaseFee(ctx context.Context) (_ *big.Int, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var head *types.Header
+	err = c.retry(ctx, func() (err error) {
+		head, err = c.Client.HeaderByNumber(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if head.BaseFee == nil {
+		return nil, ErrNoBaseFee
+	}
+	return nextBaseFee(head), nil
+}
+
+// SuggestFees returns the current gas price and, on EIP-1559 chains, the base
+// fees and the priority fee and fee cap transactions are sent with by default
+func (c *Client) SuggestFees(ctx context.Context) (_ *FeeSuggestion, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var gasPrice *big.Int
+	var head *types.Header
+	err = c.retry(ctx, func() (err error) {
+		if gasPrice, err = c.Client.SuggestGasPrice(ctx); err != nil {
+			return err
+		}
+		head, err = c.Client.HeaderByNumber(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest fees: %w", err)
+	}
+
+	suggestion := &FeeSuggestion{GasPrice: gasPrice.String()}
+	if head.BaseFee == nil {
+		return suggestion, nil
+	}
+
+	var tip *big.Int
+	err = c.retry(ctx, func() (err error) {
+		tip, err = c.Client.SuggestGasTipCap(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+
+	next := nextBaseFee(head)
+	suggestion.BaseFee = head.BaseFee.String()
+	suggestion.NextBaseFee = next.String()
+	suggestion.MaxPriorityFee = tip.String()
+	suggestion.MaxFee = defaultMaxFee(next, tip).String()
+	return suggestion, nil
+}
+
+// nextBaseFee computes the base fee of the block after head, which must have a base fee
+func nextBaseFee(head *types.Header) *big.Int {
+	if head.GasLimit == 0 {
+		return new(big.Int).Set(head.BaseFee)
+	}
+	return eip1559.CalcBaseFee(londonRules, head)
+}
+
+// defaultMaxFee leaves room for the base fee to double before a transaction is included
+func defaultMaxFee(baseFee, tip *big.Int) *big.Int {
+	return new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+}
@@ -0,0 +1,137 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// GasStrategy is a speed tier, trading the fees paid for a transaction against
+// how soon it is likely to be included
+type GasStrategy string
+
+// Gas strategies, from the cheapest to the fastest
+const (
+	GasStrategySlow     GasStrategy = "slow"
+	GasStrategyStandard GasStrategy = "standard"
+	GasStrategyFast     GasStrategy = "fast"
+)
+
+// strategyHistoryBlocks is the number of recent blocks the tier fees are derived from
+const strategyHistoryBlocks = 20
+
+// strategyPercentiles maps each tier to the percentile of the priority fees
+// paid in recent blocks that it pays
+var strategyPercentiles = map[GasStrategy]float64{
+	GasStrategySlow:     10,
+	GasStrategyStandard: 50,
+	GasStrategyFast:     90,
+}
+
+// strategyLegacyPercents scales the node's suggested gas price for each tier
+// on chains without a base fee, where fee history carries no priority fees
+var strategyLegacyPercents = map[GasStrategy]int64{
+	GasStrategySlow:     90,
+	GasStrategyStandard: 100,
+	GasStrategyFast:     125,
+}
+
+// ParseGasStrategy parses a tier name, defaulting to Standard when empty
+func ParseGasStrategy(s string) (GasStrategy, error) {
+	if s == "" {
+		return GasStrategyStandard, nil
+	}
+	strategy := GasStrategy(s)
+	if _, ok := strategyPercentiles[strategy]; !ok {
+		return "", fmt.Errorf("invalid speed %q: must be slow, standard or fast", s)
+	}
+	return strategy, nil
+}
+
+// SuggestGasPriceForStrategy returns the gas price a transaction of the given
+// tier pays. On EIP-1559 chains this is the next base fee plus the tier's
+// priority fee; elsewhere, the node's suggested gas price scaled for the tier.
+func (c *Client) SuggestGasPriceForStrategy(ctx context.Context, strategy GasStrategy) (_ *big.Int, err error) {
+	if _, ok := strategyPercentiles[strategy]; !ok {
+		return nil, fmt.Errorf("unknown gas strategy %q", strategy)
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var head *types.Header
+	err = c.retry(ctx, func() (err error) {
+		head, err = c.Client.HeaderByNumber(ctx, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	if head.BaseFee == nil {
+		return c.strategyGasPrice(ctx, strategy)
+	}
+	tip, err := c.strategyTip(ctx, strategy)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Add(nextBaseFee(head), tip), nil
+}
+
+// strategyTip returns the priority fee of a tier: the median, over the recent
+// blocks that were not empty, of the tier's percentile of the priority fees
+// paid in each block. It falls back to the node's suggestion when there are
+// no such blocks.
+func (c *Client) strategyTip(ctx context.Context, strategy GasStrategy) (*big.Int, error) {
+	var history *ethereum.FeeHistory
+	err := c.retry(ctx, func() (err error) {
+		history, err = c.Client.FeeHistory(ctx, strategyHistoryBlocks, nil, []float64{strategyPercentiles[strategy]})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+
+	var tips []*big.Int
+	for i, rewards := range history.Reward {
+		if len(rewards) == 0 || (i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0) {
+			continue
+		}
+		tips = append(tips, rewards[0])
+	}
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		return new(big.Int).Set(tips[len(tips)/2]), nil
+	}
+
+	var tip *big.Int
+	err = c.retry(ctx, func() (err error) {
+		tip, err = c.Client.SuggestGasTipCap(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+	return tip, nil
+}
+
+// strategyGasPrice returns the legacy gas price of a tier
+func (c *Client) strategyGasPrice(ctx context.Context, strategy GasStrategy) (*big.Int, error) {
+	var gasPrice *big.Int
+	err := c.retry(ctx, func() (err error) {
+		gasPrice, err = c.Client.SuggestGasPrice(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+	gasPrice.Mul(gasPrice, big.NewInt(strategyLegacyPercents[strategy]))
+	return gasPrice.Div(gasPrice, big.NewInt(100)), nil
+}
@@ -0,0 +1,158 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// MaxHoldingsScanRange is the largest block range scanned for Transfer logs by GetTokenHoldings
+	MaxHoldingsScanRange = 100000
+	// holdingsBalanceBatch is the number of balanceOf calls sent per batched RPC call
+	holdingsBalanceBatch = 100
+)
+
+// ErrScanRangeTooLarge is returned when a scan would cover more than MaxHoldingsScanRange blocks
+var ErrScanRangeTooLarge = fmt.Errorf("scan range exceeds %d blocks", MaxHoldingsScanRange)
+
+// transferEventTopic is the topic of the ERC20 Transfer(address,address,uint256) event
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// balanceOfSelector is the selector of the ERC20 balanceOf(address) method
+var balanceOfSelector = crypto.Keccak256([]byte("balanceOf(address)"))[:4]
+
+// TokenBalance is the balance of an ERC20 token held by an address, in the token's base units
+type TokenBalance struct {
+	Token   common.Address
+	Balance *big.Int
+}
+
+// GetTokenHoldings discovers the ERC20 tokens an address holds by scanning the
+// Transfer logs sent to or from it since fromBlock, and returns the current
+// non-zero balance of each token in order of discovery. A fromBlock of 0 scans
+// from genesis, or the largest allowed range up to the head on longer chains.
+// Tokens whose balanceOf fails are skipped. The node must keep logs for the
+// whole range.
+func (c *Client) GetTokenHoldings(ctx context.Context, address string, fromBlock uint64) (_ []TokenBalance, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	account := common.HexToAddress(address)
+
+	var head uint64
+	err = c.retry(ctx, func() (err error) {
+		head, err = c.Client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	// Default to the range ending at the head just read, so it can't exceed the limit
+	from := fromBlock
+	if from == 0 && head >= MaxHoldingsScanRange {
+		from = head - MaxHoldingsScanRange + 1
+	}
+	if from > head {
+		return nil, fmt.Errorf("fromBlock %d is after the chain head %d", from, head)
+	}
+	if head-from >= MaxHoldingsScanRange {
+		return nil, ErrScanRangeTooLarge
+	}
+
+	tokens, err := c.transferredTokens(ctx, account, from, head)
+	if err != nil {
+		return nil, err
+	}
+	return c.tokenBalances(ctx, account, tokens)
+}
+
+// transferredTokens returns the contracts that emitted an ERC20 Transfer to or
+// from the account within the block range, in order of first transfer
+func (c *Client) transferredTokens(ctx context.Context, account common.Address, fromBlock, toBlock uint64) ([]common.Address, error) {
+	accountTopic := common.BytesToHash(account.Bytes())
+	queries := [][][]common.Hash{
+		{{transferEventTopic}, {accountTopic}},
+		{{transferEventTopic}, nil, {accountTopic}},
+	}
+
+	var tokens []common.Address
+	seen := make(map[common.Address]bool)
+	for start := fromBlock; start <= toBlock; start += logsBlockRange {
+		end := min(start+logsBlockRange-1, toBlock)
+		for _, topics := range queries {
+			var logs []types.Log
+			err := c.retry(ctx, func() (err error) {
+				logs, err = c.Client.FilterLogs(ctx, ethereum.FilterQuery{
+					FromBlock: new(big.Int).SetUint64(start),
+					ToBlock:   new(big.Int).SetUint64(end),
+					Topics:    topics,
+				})
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get transfer logs: %w", err)
+			}
+
+			for _, vLog := range logs {
+				// ERC721 transfers share the signature but index the token id too
+				if len(vLog.Topics) != 3 || seen[vLog.Address] {
+					continue
+				}
+				seen[vLog.Address] = true
+				tokens = append(tokens, vLog.Address)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// tokenBalances queries the account's balance of each token in batched calls,
+// leaving out zero balances and tokens whose balanceOf fails
+func (c *Client) tokenBalances(ctx context.Context, account common.Address, tokens []common.Address) ([]TokenBalance, error) {
+	callData := append(append([]byte{}, balanceOfSelector...), common.LeftPadBytes(account.Bytes(), 32)...)
+
+	holdings := []TokenBalance{}
+	for start := 0; start < len(tokens); start += holdingsBalanceBatch {
+		batch := tokens[start:min(start+holdingsBalanceBatch, len(tokens))]
+		results := make([]hexutil.Bytes, len(batch))
+		reqs := make([]rpc.BatchElem, len(batch))
+		for i, token := range batch {
+			reqs[i] = rpc.BatchElem{
+				Method: "eth_call",
+				Args: []interface{}{map[string]interface{}{
+					"to":   token,
+					"data": hexutil.Bytes(callData),
+				}, "latest"},
+				Result: &results[i],
+			}
+		}
+
+		if err := c.retry(ctx, func() error { return c.Client.Client().BatchCallContext(ctx, reqs) }); err != nil {
+			return nil, fmt.Errorf("failed to get token balances: %w", err)
+		}
+
+		for i, req := range reqs {
+			if req.Error != nil || len(results[i]) < 32 {
+				continue
+			}
+			balance := new(big.Int).SetBytes(results[i][:32])
+			if balance.Sign() == 0 {
+				continue
+			}
+			holdings = append(holdings, TokenBalance{Token: batch[i], Balance: balance})
+		}
+	}
+	return holdings, nil
+}
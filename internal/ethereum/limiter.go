@@ -0,0 +1,81 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrRPCSaturated is returned when no RPC slot frees up before the context ends.
+// It doesn't wrap the context error so the breaker doesn't count it as a node failure.
+var ErrRPCSaturated = errors.New("too many concurrent RPC calls")
+
+// rpcMetrics exposes the number of RPC calls in flight and waiting for a slot
+var rpcMetrics = expvar.NewMap("ethereum_rpc")
+
+// rpcLimiter bounds the number of RPC calls in flight to the node
+type rpcLimiter struct {
+	slots    chan struct{} // nil when unlimited
+	inFlight atomic.Int64
+	waiting  atomic.Int64
+}
+
+// newRPCLimiter creates a limiter allowing max concurrent calls. A max of
+// zero or less disables the limit.
+func newRPCLimiter(max int) *rpcLimiter {
+	l := &rpcLimiter{}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+	rpcMetrics.Set("in_flight", expvar.Func(func() interface{} {
+		return l.inFlight.Load()
+	}))
+	rpcMetrics.Set("waiting", expvar.Func(func() interface{} {
+		return l.waiting.Load()
+	}))
+	rpcMetrics.Set("max_concurrent", expvar.Func(func() interface{} {
+		return cap(l.slots)
+	}))
+	return l
+}
+
+// acquire blocks until a slot is free or the context ends
+func (l *rpcLimiter) acquire(ctx context.Context) error {
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			l.waiting.Add(1)
+			select {
+			case l.slots <- struct{}{}:
+				l.waiting.Add(-1)
+			case <-ctx.Done():
+				l.waiting.Add(-1)
+				rpcMetrics.Add("saturated", 1)
+				return fmt.Errorf("%w: %v", ErrRPCSaturated, ctx.Err())
+			}
+		}
+	}
+	l.inFlight.Add(1)
+	return nil
+}
+
+// release frees a slot taken by acquire
+func (l *rpcLimiter) release() {
+	l.inFlight.Add(-1)
+	if l.slots != nil {
+		<-l.slots
+	}
+}
+
+// limit runs fn, which makes a single RPC call, within a concurrency slot.
+// Long-lived subscriptions don't hold a slot.
+func (c *Client) limit(ctx context.Context, fn func() error) error {
+	if err := c.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer c.limiter.release()
+	return fn()
+}
@@ -0,0 +1,121 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// logsBlockRange is the number of blocks requested per eth_getLogs call
+	logsBlockRange = 2000
+	// logsMaxCallsPerPage bounds the eth_getLogs calls made for a single page
+	logsMaxCallsPerPage = 10
+)
+
+// LogQuery selects historical logs by emitting contract, topics and block range
+type LogQuery struct {
+	Addresses []common.Address
+	Topics    [][]common.Hash
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+// LogPosition identifies a log by its block number and index in the block.
+// Positions order logs deterministically across pages.
+type LogPosition struct {
+	BlockNumber uint64
+	Index       uint
+}
+
+// before reports whether the position comes before the log
+func (p LogPosition) before(vLog types.Log) bool {
+	if vLog.BlockNumber != p.BlockNumber {
+		return vLog.BlockNumber > p.BlockNumber
+	}
+	return vLog.Index > p.Index
+}
+
+// LogsPage is a page of historical logs
+type LogsPage struct {
+	Logs []types.Log
+	Next *LogPosition // Resumes the next page; nil once the range is exhausted
+	// Truncated is set when the context deadline cut the scan short
+	Truncated bool
+}
+
+// GetLogsPage returns up to limit logs matching the query that come after the
+// given position (or from the start of the range if nil), in chain order.
+// Large ranges are scanned in block windows, so a page may be short or empty
+// while still returning a position to continue from. If the context deadline
+// expires after some progress, the partial page is returned as truncated.
+func (c *Client) GetLogsPage(ctx context.Context, query LogQuery, after *LogPosition, limit int) (_ *LogsPage, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	start := query.FromBlock
+	if after != nil && after.BlockNumber > start {
+		start = after.BlockNumber
+	}
+
+	var page []types.Log
+	for calls := 0; start <= query.ToBlock; calls++ {
+		if calls == logsMaxCallsPerPage {
+			// Everything before start has been scanned, resume from there
+			return &LogsPage{Logs: page, Next: &LogPosition{BlockNumber: start - 1, Index: math.MaxUint}}, nil
+		}
+
+		end := min(start+logsBlockRange-1, query.ToBlock)
+		var logs []types.Log
+		err := c.retry(ctx, func() (err error) {
+			logs, err = c.Client.FilterLogs(ctx, ethereum.FilterQuery{
+				FromBlock: new(big.Int).SetUint64(start),
+				ToBlock:   new(big.Int).SetUint64(end),
+				Addresses: query.Addresses,
+				Topics:    query.Topics,
+			})
+			return err
+		})
+		if err != nil {
+			if calls > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return &LogsPage{
+					Logs:      page,
+					Next:      &LogPosition{BlockNumber: start - 1, Index: math.MaxUint},
+					Truncated: true,
+				}, nil
+			}
+			return nil, fmt.Errorf("failed to get logs: %w", err)
+		}
+
+		sort.Slice(logs, func(i, j int) bool {
+			if logs[i].BlockNumber != logs[j].BlockNumber {
+				return logs[i].BlockNumber < logs[j].BlockNumber
+			}
+			return logs[i].Index < logs[j].Index
+		})
+
+		for _, vLog := range logs {
+			if after != nil && !after.before(vLog) {
+				continue
+			}
+			page = append(page, vLog)
+			if len(page) == limit {
+				return &LogsPage{Logs: page, Next: &LogPosition{BlockNumber: vLog.BlockNumber, Index: vLog.Index}}, nil
+			}
+		}
+
+		start = end + 1
+	}
+
+	return &LogsPage{Logs: page}, nil
+}
@@ -0,0 +1,84 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// nonceManager hands out consecutive nonces for the client's account, so
+// transactions sent concurrently or in quick succession don't reuse the
+// pending nonce reported by the node before the previous one is in its pool
+type nonceManager struct {
+	mu       sync.Mutex
+	next     uint64
+	valid    bool     // Cleared when the next nonce must be re-read from the node
+	released []uint64 // Nonces below next given back unused, in ascending order
+}
+
+// reserveNonce returns the next nonce for the client's account. Nonces
+// released by failed transactions are handed out first, lowest first, so no
+// gap holds later transactions back. It follows the node's pending nonce when
+// transactions were sent from elsewhere.
+func (c *Client) reserveNonce(ctx context.Context) (uint64, error) {
+	c.nonces.mu.Lock()
+	defer c.nonces.mu.Unlock()
+
+	var pending uint64
+	err := c.limit(ctx, func() (err error) {
+		pending, err = c.Client.PendingNonceAt(ctx, c.fromAddress)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
+	}
+	if !c.nonces.valid || pending > c.nonces.next {
+		c.nonces.next = pending
+		c.nonces.valid = true
+		c.nonces.released = nil
+	}
+
+	// Released nonces below the pending one were used from elsewhere
+	for len(c.nonces.released) > 0 && c.nonces.released[0] < pending {
+		c.nonces.released = c.nonces.released[1:]
+	}
+	if len(c.nonces.released) > 0 {
+		nonce := c.nonces.released[0]
+		c.nonces.released = c.nonces.released[1:]
+		return nonce, nil
+	}
+
+	nonce := c.nonces.next
+	c.nonces.next++
+	return nonce, nil
+}
+
+// releaseNonce gives back a nonce whose transaction failed with err, to be
+// reserved again before any new one. If the node may have received the
+// transaction, the next nonce is re-read from the node instead.
+func (c *Client) releaseNonce(nonce uint64, err error) {
+	c.nonces.mu.Lock()
+	defer c.nonces.mu.Unlock()
+
+	var broadcastErr *BroadcastError
+	if isNodeFailure(err) || errors.As(err, &broadcastErr) {
+		c.nonces.valid = false
+		c.nonces.released = nil
+		return
+	}
+	if !c.nonces.valid || nonce >= c.nonces.next {
+		return
+	}
+
+	i, found := slices.BinarySearch(c.nonces.released, nonce)
+	if !found {
+		c.nonces.released = slices.Insert(c.nonces.released, i, nonce)
+	}
+	// Wind next back over released nonces at the top
+	for n := len(c.nonces.released); n > 0 && c.nonces.released[n-1]+1 == c.nonces.next; n-- {
+		c.nonces.next--
+		c.nonces.released = c.nonces.released[:n-1]
+	}
+}
@@ -0,0 +1,30 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// RawCall forwards a JSON-RPC call to the node as is and returns the raw result.
+// Errors returned by the node implement rpc.Error.
+func (c *Client) RawCall(ctx context.Context, method string, params []json.RawMessage) (_ json.RawMessage, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	args := make([]interface{}, len(params))
+	for i, param := range params {
+		args[i] = param
+	}
+
+	var result json.RawMessage
+	err = c.limit(ctx, func() error {
+		return c.Client.Client().CallContext(ctx, &result, method, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
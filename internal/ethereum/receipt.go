@@ -0,0 +1,66 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// defaultReceiptPollInterval is used when no receipt poll interval is configured
+	defaultReceiptPollInterval = 4 * time.Second
+	// defaultReceiptWaitTimeout is used when no receipt wait timeout is configured
+	defaultReceiptWaitTimeout = 5 * time.Minute
+)
+
+// ErrReceiptTimeout is returned when a transaction isn't mined within the receipt wait timeout
+var ErrReceiptTimeout = errors.New("timed out waiting for the transaction receipt")
+
+// receiptPollInterval returns how often to check for a receipt while waiting for one
+func (c *Client) receiptPollInterval() time.Duration {
+	if c.config.ReceiptPollInterval <= 0 {
+		return defaultReceiptPollInterval
+	}
+	return c.config.ReceiptPollInterval
+}
+
+// receiptWaitTimeout returns how long to wait for a receipt before giving up
+func (c *Client) receiptWaitTimeout() time.Duration {
+	if c.config.ReceiptWaitTimeout <= 0 {
+		return defaultReceiptWaitTimeout
+	}
+	return c.config.ReceiptWaitTimeout
+}
+
+// WaitForReceipt polls for the receipt of a transaction every
+// ethereum.receiptPollInterval until it is mined, and returns ErrReceiptTimeout
+// when it isn't within ethereum.receiptWaitTimeout
+func (c *Client) WaitForReceipt(ctx context.Context, txHash string) (*types.Receipt, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.receiptWaitTimeout())
+	defer cancel()
+
+	ticker := time.NewTicker(c.receiptPollInterval())
+	defer ticker.Stop()
+	for {
+		receipt, err := c.GetTransactionReceipt(ctx, txHash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) && ctx.Err() == nil {
+			return nil, err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %s", ErrReceiptTimeout, txHash)
+			}
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// feeBumpPercent is the fee increase of a replacement transaction. Nodes
+	// only accept replacements paying at least 10% more.
+	feeBumpPercent = 12.5
+	// replaceAfterBlocks is the number of blocks SendWithDeadline waits for a
+	// transaction before replacing it with higher fees
+	replaceAfterBlocks = 3
+)
+
+// ErrDeadlineExceeded is returned when a transaction sent with SendWithDeadline
+// wasn't mined within its deadline
+var ErrDeadlineExceeded = errors.New("transaction not confirmed before the deadline")
+
+// ReplaceTransaction resends a pending transaction of the client's account
+// with the same nonce and contents but higher fees, so that it gets mined
+// sooner. Each fee is raised by at least feeBumpPercent, or to the current
+// market fee if that is higher.
+func (c *Client) ReplaceTransaction(ctx context.Context, tx *types.Transaction) (_ *types.Transaction, err error) {
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+	default:
+		return nil, fmt.Errorf("replacing transactions of type %d is not supported", tx.Type())
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	nonce := tx.Nonce()
+	opts := TxOptions{
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		Nonce:      &nonce,
+		GasLimit:   tx.Gas(),
+		AccessList: tx.AccessList(),
+	}
+
+	if tx.Type() == types.DynamicFeeTxType {
+		current := TxOptions{}
+		if err := c.fillDynamicFees(ctx, &current); err != nil {
+			return nil, err
+		}
+		opts.MaxPriorityFee = bumpFee(tx.GasTipCap(), current.MaxPriorityFee)
+		opts.MaxFee = bumpFee(tx.GasFeeCap(), current.MaxFee)
+		if opts.MaxFee.Cmp(opts.MaxPriorityFee) < 0 {
+			opts.MaxFee = opts.MaxPriorityFee
+		}
+	} else {
+		var gasPrice *big.Int
+		err := c.limit(ctx, func() (err error) {
+			gasPrice, err = c.Client.SuggestGasPrice(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		opts.GasPrice = bumpFee(tx.GasPrice(), gasPrice)
+	}
+
+	replacement, err := c.buildTransaction(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return c.signAndSend(ctx, replacement)
+}
+
+// bumpFee raises a fee by feeBumpPercent, or to current if that is higher
+func bumpFee(fee, current *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(int64(1000+feeBumpPercent*10)))
+	bumped.Div(bumped, big.NewInt(1000))
+	bumped.Add(bumped, big.NewInt(1)) // Round up so small fees still rise by enough
+	if current != nil && current.Cmp(bumped) > 0 {
+		return new(big.Int).Set(current)
+	}
+	return bumped
+}
+
+// SendWithDeadline sends amount to an address and makes sure the transfer is
+// mined within maxBlocks blocks: while it stays pending, it is replaced with
+// higher fees every replaceAfterBlocks blocks. It returns the hash of the
+// version that was mined, or ErrDeadlineExceeded (with the hash of the last
+// version sent) once the deadline passes. A transfer that is still pending
+// then may be mined later.
+func (c *Client) SendWithDeadline(ctx context.Context, to string, amount *big.Int, maxBlocks uint64) (string, error) {
+	toAddress := common.HexToAddress(to)
+	tx, err := c.sendWithOptions(ctx, TxOptions{To: &toAddress, Value: amount})
+	if err != nil {
+		return "", err
+	}
+
+	start, err := c.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return tx.Hash().Hex(), err
+	}
+	deadline := start + maxBlocks
+	lastSent := start
+
+	// Any of the versions sent may be the one that gets mined
+	sent := []*types.Transaction{tx}
+
+	ticker := time.NewTicker(c.receiptPollInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return tx.Hash().Hex(), ctx.Err()
+		}
+
+		hash, mined, err := c.minedVersion(ctx, sent)
+		if err != nil {
+			return tx.Hash().Hex(), err
+		}
+		if mined {
+			return hash.Hex(), nil
+		}
+
+		head, err := c.GetLatestBlockNumber(ctx)
+		if err != nil {
+			return tx.Hash().Hex(), err
+		}
+		if head >= deadline {
+			return tx.Hash().Hex(), fmt.Errorf("%w: not mined within %d blocks", ErrDeadlineExceeded, maxBlocks)
+		}
+		if head-lastSent < replaceAfterBlocks {
+			continue
+		}
+
+		replacement, err := c.ReplaceTransaction(ctx, tx)
+		var broadcastErr *BroadcastError
+		switch {
+		case errors.As(err, &broadcastErr):
+			// The replacement may have reached the node, so it may get mined
+			replacement = broadcastErr.Tx
+		case isReplacementRace(err):
+			// An earlier version was mined meanwhile, or the node already
+			// has this one; the next receipt check tells
+			continue
+		case err != nil:
+			return tx.Hash().Hex(), err
+		}
+		tx = replacement
+		sent = append(sent, replacement)
+		lastSent = head
+	}
+}
+
+// isReplacementRace reports whether a replacement was rejected because the
+// nonce was used meanwhile or the node already has the transaction. These
+// errors come from the node as messages, so they are matched by text.
+func isReplacementRace(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "already known")
+}
+
+// minedVersion returns the hash of the transaction among versions that was mined, if any
+func (c *Client) minedVersion(ctx context.Context, versions []*types.Transaction) (common.Hash, bool, error) {
+	for _, version := range versions {
+		receipt, err := c.GetTransactionReceipt(ctx, version.Hash().Hex())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return common.Hash{}, false, err
+		}
+		return receipt.TxHash, true, nil
+	}
+	return common.Hash{}, false, nil
+}
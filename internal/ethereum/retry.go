@@ -0,0 +1,107 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// retryMetrics exposes the number of retried and exhausted read calls
+var retryMetrics = expvar.NewMap("ethereum_retries")
+
+// retryPolicy retries read calls failing with transient errors, with
+// exponential backoff and jitter between attempts
+type retryPolicy struct {
+	attempts int // Total attempts, including the first call
+	initial  time.Duration
+	max      time.Duration
+}
+
+// retry calls fn until it succeeds, fails with an error that isn't transient,
+// or the attempts are exhausted. It gives up early rather than sleeping past
+// the context deadline. Each attempt holds an RPC slot; the backoff between
+// attempts doesn't. Only idempotent read calls may be retried.
+func (c *Client) retry(ctx context.Context, fn func() error) error {
+	policy := c.retryPolicy
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.limit(ctx, fn)
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt+1 >= policy.attempts {
+			if policy.attempts > 1 {
+				retryMetrics.Add("exhausted", 1)
+			}
+			return err
+		}
+
+		delay := policy.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		retryMetrics.Add("retries", 1)
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt:
+// the initial delay doubled for each attempt, capped at the maximum, with
+// up to half of it randomized to spread out clients retrying together
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	delay := p.initial << attempt
+	if delay <= 0 || delay > p.max {
+		delay = p.max
+	}
+	if half := int64(delay / 2); half > 0 {
+		delay = time.Duration(half + rand.Int64N(half+1))
+	}
+	return delay
+}
+
+// isRetryable reports whether an error is transient: rate limiting, a
+// dropped connection or a failing node. Errors returned by the node for the
+// call itself, such as reverts or invalid parameters, are not retried.
+func isRetryable(err error) bool {
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+	var rpcErr rpc.Error
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return false
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= 500
+	case errors.As(err, &netErr):
+		return true
+	case errors.As(err, &rpcErr):
+		// -32005 is the "limit exceeded" code used by rate limiting providers
+		return rpcErr.ErrorCode() == -32005 || isRateLimitMessage(rpcErr.Error())
+	}
+	return isRateLimitMessage(err.Error())
+}
+
+// isRateLimitMessage reports whether an error message indicates rate limiting
+func isRateLimitMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "too many requests") || strings.Contains(message, "rate limit")
+}
@@ -0,0 +1,36 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// RecoverSender recovers the address that signed a transaction. Legacy
+// transactions signed without replay protection (pre-EIP-155) are recovered
+// with the homestead signer; all others with the latest signer for the chain.
+// A nil chainID uses the chain ID embedded in the transaction.
+func RecoverSender(tx *types.Transaction, chainID *big.Int) (common.Address, error) {
+	var signer types.Signer
+	if tx.Type() == types.LegacyTxType && !tx.Protected() {
+		signer = types.HomesteadSigner{}
+	} else {
+		if chainID == nil {
+			chainID = tx.ChainId()
+		}
+		signer = types.LatestSignerForChainID(chainID)
+	}
+
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover sender of %s: %w", tx.Hash().Hex(), err)
+	}
+	return from, nil
+}
+
+// ConfiguredChainID returns the chain ID the client is configured for
+func (c *Client) ConfiguredChainID() *big.Int {
+	return big.NewInt(c.config.ChainID)
+}
@@ -0,0 +1,131 @@
+package ethereum
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Signer signs transactions and messages for the client's account
+type Signer interface {
+	// Address returns the account the signer signs for
+	Address() common.Address
+	// SignTx signs a transaction for the given chain
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignMessage signs an EIP-191 personal message, returning a signature with V of 27 or 28
+	SignMessage(message []byte) ([]byte, error)
+}
+
+// localSigner signs with a private key held in memory
+type localSigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewLocalSigner creates a signer from a hex-encoded private key
+func NewLocalSigner(hexKey string) (Signer, error) {
+	key, err := crypto.HexToECDSA(hexKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	return &localSigner{
+		key:     key,
+		address: crypto.PubkeyToAddress(key.PublicKey),
+	}, nil
+}
+
+// Address returns the address of the private key
+func (s *localSigner) Address() common.Address {
+	return s.address
+}
+
+// SignTx signs a transaction with the private key
+func (s *localSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+// SignMessage signs an EIP-191 personal message with the private key
+func (s *localSigner) SignMessage(message []byte) ([]byte, error) {
+	signature, err := crypto.Sign(accounts.TextHash(message), s.key)
+	if err != nil {
+		return nil, err
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// clefSigner delegates signing to an external Clef instance. The connection
+// is made on first use so an unreachable Clef is reported when signing.
+type clefSigner struct {
+	url     string
+	account accounts.Account
+
+	mu     sync.Mutex
+	signer *external.ExternalSigner
+}
+
+// NewClefSigner creates a signer for the given account backed by the Clef HTTP API at url
+func NewClefSigner(url string, address common.Address) Signer {
+	return &clefSigner{
+		url:     url,
+		account: accounts.Account{Address: address},
+	}
+}
+
+// Address returns the Clef account used for signing
+func (s *clefSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignTx asks Clef to sign a transaction
+func (s *clefSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := signer.SignTx(s.account, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("clef: %w", err)
+	}
+	return signed, nil
+}
+
+// SignMessage asks Clef to sign an EIP-191 personal message
+func (s *clefSigner) SignMessage(message []byte) ([]byte, error) {
+	signer, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.SignText(s.account, message)
+	if err != nil {
+		return nil, fmt.Errorf("clef: %w", err)
+	}
+	// The external signer returns V as 0/1
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// connect returns the Clef connection, dialing it if not connected yet
+func (s *clefSigner) connect() (*external.ExternalSigner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.signer == nil {
+		signer, err := external.NewExternalSigner(s.url)
+		if err != nil {
+			return nil, fmt.Errorf("clef unreachable at %s: %w", s.url, err)
+		}
+		s.signer = signer
+	}
+	return s.signer, nil
+}
@@ -0,0 +1,78 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RevertError is returned when a simulated call reverts
+type RevertError struct {
+	Reason string // Decoded Error(string) reason, if any
+	Data   []byte // Raw revert data
+}
+
+// Error implements the error interface
+func (e *RevertError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("execution reverted: %s", e.Reason)
+	}
+	if len(e.Data) > 0 {
+		return fmt.Sprintf("execution reverted: %s", hexutil.Encode(e.Data))
+	}
+	return "execution reverted"
+}
+
+// SimulateTransaction executes the transaction with eth_call from the client's
+// address against the pending state. It returns a *RevertError with the decoded
+// reason if the transaction would revert, or nil if it would succeed.
+func (c *Client) SimulateTransaction(ctx context.Context, to string, amount *big.Int, data []byte) (err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	toAddress := common.HexToAddress(to)
+
+	err = c.limit(ctx, func() error {
+		_, err := c.Client.PendingCallContract(ctx, ethereum.CallMsg{
+			From:  c.fromAddress,
+			To:    &toAddress,
+			Value: amount,
+			Data:  data,
+		})
+		return err
+	})
+	if err != nil {
+		if revertErr := asRevertError(err); revertErr != nil {
+			return revertErr
+		}
+		return fmt.Errorf("failed to simulate transaction: %w", err)
+	}
+	return nil
+}
+
+// asRevertError extracts the revert data from an eth_call error, if present
+func asRevertError(err error) *RevertError {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil
+	}
+	return newRevertError(data)
+}
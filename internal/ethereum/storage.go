@@ -0,0 +1,51 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// eip1967ImplementationSlot is the EIP-1967 storage slot holding a proxy's
+// implementation address: keccak256("eip1967.proxy.implementation") - 1
+var eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+
+// ErrNotProxy is returned when an address has no EIP-1967 implementation slot set
+var ErrNotProxy = errors.New("not an EIP-1967 proxy: implementation slot is empty")
+
+// GetStorageAt returns the value of a storage slot of an address at the latest block
+func (c *Client) GetStorageAt(ctx context.Context, address string, slot common.Hash) (_ common.Hash, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	account := common.HexToAddress(address)
+	var value []byte
+	err = c.retry(ctx, func() (err error) {
+		value, err = c.Client.StorageAt(ctx, account, slot, nil)
+		return err
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get storage: %w", err)
+	}
+	return common.BytesToHash(value), nil
+}
+
+// GetProxyImplementation returns the implementation address of an EIP-1967
+// proxy, read from its implementation storage slot
+func (c *Client) GetProxyImplementation(ctx context.Context, address string) (common.Address, error) {
+	value, err := c.GetStorageAt(ctx, address, eip1967ImplementationSlot)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	implementation := common.BytesToAddress(value.Bytes())
+	if implementation == (common.Address{}) {
+		return common.Address{}, ErrNotProxy
+	}
+	return implementation, nil
+}
@@ -0,0 +1,114 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoCode is returned when a contract call targets an address without code
+var ErrNoCode = errors.New("no contract code at address")
+
+// erc20MetadataABI covers the optional ERC20 metadata methods
+const erc20MetadataABI = `[
+	{"type":"function","name":"name","inputs":[],"outputs":[{"name":"","type":"string"}],"stateMutability":"view"},
+	{"type":"function","name":"symbol","inputs":[],"outputs":[{"name":"","type":"string"}],"stateMutability":"view"},
+	{"type":"function","name":"decimals","inputs":[],"outputs":[{"name":"","type":"uint8"}],"stateMutability":"view"}
+]`
+
+// TokenInfo holds the metadata of an ERC20 token
+type TokenInfo struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// GetTokenInfo returns the name, symbol and decimals of an ERC20 token.
+// The code check and the three calls are sent in a single batched RPC round trip.
+// Name and symbol are optional in ERC20 and left empty if the token lacks them.
+func (c *Client) GetTokenInfo(ctx context.Context, tokenAddr string) (_ TokenInfo, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return TokenInfo{}, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	token := common.HexToAddress(tokenAddr)
+
+	parsed, err := parseABI(erc20MetadataABI)
+	if err != nil {
+		return TokenInfo{}, err
+	}
+
+	var code hexutil.Bytes
+	reqs := []rpc.BatchElem{{
+		Method: "eth_getCode",
+		Args:   []interface{}{token, "latest"},
+		Result: &code,
+	}}
+
+	methods := []string{"name", "symbol", "decimals"}
+	results := make([]hexutil.Bytes, len(methods))
+	for i, method := range methods {
+		reqs = append(reqs, rpc.BatchElem{
+			Method: "eth_call",
+			Args: []interface{}{map[string]interface{}{
+				"to":   token,
+				"data": hexutil.Bytes(parsed.Methods[method].ID),
+			}, "latest"},
+			Result: &results[i],
+		})
+	}
+
+	if err := c.retry(ctx, func() error { return c.Client.Client().BatchCallContext(ctx, reqs) }); err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to get token info: %w", err)
+	}
+	if reqs[0].Error != nil {
+		return TokenInfo{}, fmt.Errorf("failed to get code: %w", reqs[0].Error)
+	}
+	if len(code) == 0 {
+		return TokenInfo{}, ErrNoCode
+	}
+
+	info := TokenInfo{Address: token.Hex()}
+	calls := reqs[1:]
+	if calls[0].Error == nil {
+		info.Name = decodeStringOrBytes32(parsed.Methods["name"], results[0])
+	}
+	if calls[1].Error == nil {
+		info.Symbol = decodeStringOrBytes32(parsed.Methods["symbol"], results[1])
+	}
+
+	if calls[2].Error != nil {
+		return TokenInfo{}, fmt.Errorf("failed to get decimals: %w", calls[2].Error)
+	}
+	out, err := parsed.Methods["decimals"].Outputs.Unpack(results[2])
+	if err != nil || len(out) == 0 {
+		return TokenInfo{}, fmt.Errorf("failed to decode decimals: invalid return data")
+	}
+	info.Decimals = out[0].(uint8)
+
+	return info, nil
+}
+
+// decodeStringOrBytes32 decodes a string return value. Older tokens (e.g. MKR)
+// return a NUL-padded bytes32 instead, which is recognised by its fixed length.
+func decodeStringOrBytes32(method abi.Method, data []byte) string {
+	if len(data) == 32 {
+		return string(bytes.TrimRight(data, "\x00"))
+	}
+
+	out, err := method.Outputs.Unpack(data)
+	if err != nil || len(out) == 0 {
+		return ""
+	}
+	s, _ := out[0].(string)
+	return s
+}
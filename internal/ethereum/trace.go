@@ -0,0 +1,202 @@
+package ethereum
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrTracingUnsupported is returned when the node exposes neither the debug nor the trace API
+var ErrTracingUnsupported = errors.New("node does not support block tracing")
+
+// Tracing APIs, in order of preference
+const (
+	traceAPIDebug  = "debug" // debug_traceBlockByNumber with the callTracer (geth)
+	traceAPIParity = "trace" // trace_block (Erigon, Nethermind, OpenEthereum)
+)
+
+// InternalTransfer is a value transfer made by a contract during a transaction
+type InternalTransfer struct {
+	TxHash common.Hash    `json:"txHash"`
+	Type   string         `json:"type"` // CALL, CREATE, CREATE2 or SELFDESTRUCT
+	From   common.Address `json:"from"`
+	To     common.Address `json:"to"`
+	Value  *big.Int       `json:"value"`
+}
+
+// MarshalJSON encodes the value in wei as an Amount, like other events
+func (t InternalTransfer) MarshalJSON() ([]byte, error) {
+	type transfer InternalTransfer
+	return json.Marshal(struct {
+		transfer
+		Value Amount `json:"value"`
+	}{transfer(t), NewAmount(t.Value)})
+}
+
+// callFrame is a call of the callTracer output
+type callFrame struct {
+	Type  string         `json:"type"`
+	From  common.Address `json:"from"`
+	To    common.Address `json:"to"`
+	Value *hexutil.Big   `json:"value"`
+	Error string         `json:"error"`
+	Calls []callFrame    `json:"calls"`
+}
+
+// txTraceResult is the per-transaction result of debug_traceBlockByNumber
+type txTraceResult struct {
+	TxHash common.Hash `json:"txHash"`
+	Result callFrame   `json:"result"`
+	Error  string      `json:"error"`
+}
+
+// parityTrace is a trace of the trace_block output
+type parityTrace struct {
+	Type   string `json:"type"`
+	Action struct {
+		CallType      string         `json:"callType"`
+		From          common.Address `json:"from"`
+		To            common.Address `json:"to"`
+		Value         *hexutil.Big   `json:"value"`
+		Address       common.Address `json:"address"`       // Selfdestructing contract
+		RefundAddress common.Address `json:"refundAddress"` // Selfdestruct beneficiary
+		Balance       *hexutil.Big   `json:"balance"`       // Selfdestructed balance
+	} `json:"action"`
+	Result *struct {
+		Address common.Address `json:"address"` // Created contract
+	} `json:"result"`
+	TraceAddress    []int       `json:"traceAddress"`
+	TransactionHash common.Hash `json:"transactionHash"`
+	Error           string      `json:"error"`
+}
+
+// DetectTracing checks which tracing API the node supports, if any.
+// The genesis block is traced as a cheap probe; only an unknown method counts as unsupported.
+func (c *Client) DetectTracing(ctx context.Context) error {
+	for _, api := range []string{traceAPIDebug, traceAPIParity} {
+		err := c.callTrace(ctx, api, 0, new([]interface{}))
+		if err != nil && isMethodNotFound(err) {
+			continue
+		}
+		c.traceAPI.Store(api)
+		return nil
+	}
+	return ErrTracingUnsupported
+}
+
+// TraceBlock returns the internal value transfers made in a block.
+// DetectTracing must have found a supported tracing API.
+func (c *Client) TraceBlock(ctx context.Context, blockNumber uint64) (_ []InternalTransfer, err error) {
+	api, _ := c.traceAPI.Load().(string)
+	if api == "" {
+		return nil, ErrTracingUnsupported
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	var transfers []InternalTransfer
+	if api == traceAPIDebug {
+		var results []txTraceResult
+		if err := c.callTrace(ctx, api, blockNumber, &results); err != nil {
+			return nil, fmt.Errorf("failed to trace block: %w", err)
+		}
+		for _, result := range results {
+			if result.Error != "" {
+				continue
+			}
+			// The top-level call is the transaction itself
+			for _, call := range result.Result.Calls {
+				transfers = collectTransfers(transfers, result.TxHash, call)
+			}
+		}
+		return transfers, nil
+	}
+
+	var traces []parityTrace
+	if err := c.callTrace(ctx, api, blockNumber, &traces); err != nil {
+		return nil, fmt.Errorf("failed to trace block: %w", err)
+	}
+	for _, trace := range traces {
+		if len(trace.TraceAddress) == 0 || trace.Error != "" {
+			continue
+		}
+		if transfer, ok := parityTransfer(trace); ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers, nil
+}
+
+// callTrace calls the block tracing method of the given API
+func (c *Client) callTrace(ctx context.Context, api string, blockNumber uint64, result interface{}) error {
+	number := hexutil.EncodeUint64(blockNumber)
+	return c.limit(ctx, func() error {
+		if api == traceAPIDebug {
+			return c.Client.Client().CallContext(ctx, result, "debug_traceBlockByNumber", number,
+				map[string]interface{}{"tracer": "callTracer"})
+		}
+		return c.Client.Client().CallContext(ctx, result, "trace_block", number)
+	})
+}
+
+// collectTransfers appends the value-carrying, successful calls of a call tree
+func collectTransfers(transfers []InternalTransfer, txHash common.Hash, call callFrame) []InternalTransfer {
+	// Reverted calls and their subcalls transferred nothing
+	if call.Error != "" {
+		return transfers
+	}
+
+	if call.Value != nil && call.Value.ToInt().Sign() > 0 {
+		transfers = append(transfers, InternalTransfer{
+			TxHash: txHash,
+			Type:   call.Type,
+			From:   call.From,
+			To:     call.To,
+			Value:  call.Value.ToInt(),
+		})
+	}
+	for _, sub := range call.Calls {
+		transfers = collectTransfers(transfers, txHash, sub)
+	}
+	return transfers
+}
+
+// parityTransfer converts a trace_block trace into a transfer if it moved value
+func parityTransfer(trace parityTrace) (InternalTransfer, bool) {
+	transfer := InternalTransfer{TxHash: trace.TransactionHash}
+
+	switch trace.Type {
+	case "call":
+		if trace.Action.CallType != "call" {
+			// delegatecall and staticcall can't move value
+			return transfer, false
+		}
+		transfer.Type = "CALL"
+		transfer.From, transfer.To = trace.Action.From, trace.Action.To
+		transfer.Value = trace.Action.Value.ToInt()
+	case "create":
+		transfer.Type = "CREATE"
+		transfer.From = trace.Action.From
+		if trace.Result != nil {
+			transfer.To = trace.Result.Address
+		}
+		transfer.Value = trace.Action.Value.ToInt()
+	case "suicide":
+		transfer.Type = "SELFDESTRUCT"
+		transfer.From, transfer.To = trace.Action.Address, trace.Action.RefundAddress
+		transfer.Value = trace.Action.Balance.ToInt()
+	default:
+		return transfer, false
+	}
+
+	return transfer, transfer.Value != nil && transfer.Value.Sign() > 0
+}
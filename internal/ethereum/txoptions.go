@@ -0,0 +1,254 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrConflictingFees is returned when both legacy and EIP-1559 fee fields are set
+var ErrConflictingFees = errors.New("gasPrice cannot be combined with maxFee or maxPriorityFee")
+
+// TxOptions describes a transaction to send. Unset fields are filled in from the node.
+//
+// The transaction type follows from the fee fields: GasPrice sends a legacy
+// transaction (type 1 if AccessList is set), MaxFee/MaxPriorityFee an EIP-1559
+// one. Without fee fields, EIP-1559 is used if the chain supports it.
+type TxOptions struct {
+	To             *common.Address // Nil deploys a contract
+	Value          *big.Int
+	Data           []byte
+	Nonce          *uint64 // Defaults to the next nonce of the account, tracked locally
+	GasLimit       uint64  // Estimated, with the configured buffer, when zero
+	GasPrice       *big.Int
+	MaxFee         *big.Int
+	MaxPriorityFee *big.Int
+	AccessList     types.AccessList
+	Speed          GasStrategy // Tier of the fees filled in; the node's suggestion when empty
+}
+
+// SentTransaction describes a transaction that was signed and sent
+type SentTransaction struct {
+	Hash     string
+	GasLimit uint64 // Gas limit the transaction was sent with, after estimation and buffering
+}
+
+// SendTransactionWithOptions fills in the missing fields of a transaction,
+// signs it for the type implied by its fee fields and sends it
+func (c *Client) SendTransactionWithOptions(ctx context.Context, opts TxOptions) (string, error) {
+	sent, err := c.SubmitTransaction(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+	return sent.Hash, nil
+}
+
+// SubmitTransaction sends a transaction like SendTransactionWithOptions and
+// also reports the gas limit it was sent with
+func (c *Client) SubmitTransaction(ctx context.Context, opts TxOptions) (*SentTransaction, error) {
+	tx, err := c.sendWithOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SentTransaction{Hash: tx.Hash().Hex(), GasLimit: tx.Gas()}, nil
+}
+
+// sendWithOptions fills in, signs and sends a transaction, returning it as sent
+func (c *Client) sendWithOptions(ctx context.Context, opts TxOptions) (_ *types.Transaction, err error) {
+	if opts.GasPrice != nil && (opts.MaxFee != nil || opts.MaxPriorityFee != nil) {
+		return nil, ErrConflictingFees
+	}
+	if opts.GasLimit != 0 && opts.GasLimit < params.TxGas {
+		return nil, ErrGasLimitTooLow
+	}
+	if _, ok := strategyPercentiles[opts.Speed]; opts.Speed != "" && !ok {
+		return nil, fmt.Errorf("unknown gas strategy %q", opts.Speed)
+	}
+
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	if opts.Nonce == nil {
+		nonce, err := c.reserveNonce(ctx)
+		if err != nil {
+			return nil, err
+		}
+		opts.Nonce = &nonce
+		defer func() {
+			if err != nil {
+				c.releaseNonce(nonce, err)
+			}
+		}()
+	}
+
+	tx, err := c.buildTransaction(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.signAndSend(ctx, tx)
+}
+
+// buildTransaction creates an unsigned transaction from the options
+func (c *Client) buildTransaction(ctx context.Context, opts TxOptions) (*types.Transaction, error) {
+	if opts.Value == nil {
+		opts.Value = big.NewInt(0)
+	}
+
+	if opts.GasPrice == nil {
+		if err := c.fillDynamicFees(ctx, &opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.GasLimit == 0 {
+		// Estimate the gas required by the transaction
+		var gasLimit uint64
+		err := c.limit(ctx, func() (err error) {
+			gasLimit, err = c.Client.EstimateGas(ctx, ethereum.CallMsg{
+				From:       c.fromAddress,
+				To:         opts.To,
+				Value:      opts.Value,
+				Data:       opts.Data,
+				GasPrice:   opts.GasPrice,
+				GasFeeCap:  opts.MaxFee,
+				GasTipCap:  opts.MaxPriorityFee,
+				AccessList: opts.AccessList,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
+		opts.GasLimit, err = c.bufferGasEstimate(ctx, gasLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chainID := big.NewInt(c.config.ChainID)
+	switch {
+	case opts.MaxFee != nil:
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      *opts.Nonce,
+			GasTipCap:  opts.MaxPriorityFee,
+			GasFeeCap:  opts.MaxFee,
+			Gas:        opts.GasLimit,
+			To:         opts.To,
+			Value:      opts.Value,
+			Data:       opts.Data,
+			AccessList: opts.AccessList,
+		}), nil
+	case opts.AccessList != nil:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      *opts.Nonce,
+			GasPrice:   opts.GasPrice,
+			Gas:        opts.GasLimit,
+			To:         opts.To,
+			Value:      opts.Value,
+			Data:       opts.Data,
+			AccessList: opts.AccessList,
+		}), nil
+	default:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    *opts.Nonce,
+			GasPrice: opts.GasPrice,
+			Gas:      opts.GasLimit,
+			To:       opts.To,
+			Value:    opts.Value,
+			Data:     opts.Data,
+		}), nil
+	}
+}
+
+// fillDynamicFees completes the EIP-1559 fee fields. On chains without a base
+// fee, and no 1559 fields given, it falls back to a legacy gas price.
+func (c *Client) fillDynamicFees(ctx context.Context, opts *TxOptions) error {
+	head, err := c.latestHeader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	if head.BaseFee == nil {
+		if opts.MaxFee != nil || opts.MaxPriorityFee != nil {
+			return fmt.Errorf("connected chain does not support EIP-1559 transactions")
+		}
+		if opts.Speed != "" {
+			opts.GasPrice, err = c.strategyGasPrice(ctx, opts.Speed)
+			return err
+		}
+		var gasPrice *big.Int
+		err := c.limit(ctx, func() (err error) {
+			gasPrice, err = c.Client.SuggestGasPrice(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		opts.GasPrice = gasPrice
+		return nil
+	}
+
+	if opts.MaxPriorityFee == nil && opts.Speed != "" {
+		opts.MaxPriorityFee, err = c.strategyTip(ctx, opts.Speed)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.MaxPriorityFee == nil {
+		var tip *big.Int
+		err := c.limit(ctx, func() (err error) {
+			tip, err = c.Client.SuggestGasTipCap(ctx)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas tip: %w", err)
+		}
+		opts.MaxPriorityFee = tip
+	}
+	if opts.MaxFee == nil {
+		opts.MaxFee = defaultMaxFee(nextBaseFee(head), opts.MaxPriorityFee)
+	}
+	if opts.MaxFee.Cmp(opts.MaxPriorityFee) < 0 {
+		return fmt.Errorf("maxFee must not be below maxPriorityFee")
+	}
+	return nil
+}
+
+// bufferGasEstimate adds the configured buffer to a gas estimate, leaving room
+// for calls whose cost varies between estimation and inclusion. The result is
+// raised to the configured minimum and capped at the block gas limit.
+func (c *Client) bufferGasEstimate(ctx context.Context, estimate uint64) (uint64, error) {
+	gasLimit := estimate + estimate*uint64(max(c.config.GasLimitBuffer, 0))/100
+	gasLimit = max(gasLimit, c.config.MinGasLimit)
+
+	head, err := c.latestHeader(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	if head.GasLimit > 0 {
+		// Never go below the estimate itself, which the node has already checked
+		gasLimit = min(gasLimit, max(head.GasLimit, estimate))
+	}
+	return gasLimit, nil
+}
+
+// latestHeader returns the header of the latest block
+func (c *Client) latestHeader(ctx context.Context) (head *types.Header, err error) {
+	err = c.limit(ctx, func() error {
+		head, err = c.Client.HeaderByNumber(ctx, nil)
+		return err
+	})
+	return head, err
+}
@@ -0,0 +1,59 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrTxPoolUnsupported is returned when the node doesn't expose the txpool API
+var ErrTxPoolUnsupported = errors.New("node does not support the txpool API (txpool_contentFrom)")
+
+// PendingTransactions holds an address's transactions in the node's pool, ordered by nonce
+type PendingTransactions struct {
+	Pending []*types.Transaction // Executable, waiting to be mined
+	Queued  []*types.Transaction // Blocked by a nonce gap
+}
+
+// GetPendingTransactions returns the pending and queued transactions sent by
+// an address, using txpool_contentFrom. Only the node's own pool is visible.
+func (c *Client) GetPendingTransactions(ctx context.Context, address string) (_ *PendingTransactions, err error) {
+	probe, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { c.breaker.Record(ctx, probe, err) }()
+
+	// Transactions are keyed by nonce in each section
+	var content map[string]map[string]*types.Transaction
+	err = c.retry(ctx, func() error {
+		return c.Client.Client().CallContext(ctx, &content, "txpool_contentFrom", common.HexToAddress(address))
+	})
+	if err != nil {
+		if isMethodNotFound(err) {
+			return nil, ErrTxPoolUnsupported
+		}
+		return nil, fmt.Errorf("failed to get pending transactions: %w", err)
+	}
+
+	return &PendingTransactions{
+		Pending: sortedByNonce(content["pending"]),
+		Queued:  sortedByNonce(content["queued"]),
+	}, nil
+}
+
+// sortedByNonce returns the transactions of a txpool section ordered by nonce
+func sortedByNonce(section map[string]*types.Transaction) []*types.Transaction {
+	txs := make([]*types.Transaction, 0, len(section))
+	for _, tx := range section {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].Nonce() < txs[j].Nonce()
+	})
+	return txs
+}
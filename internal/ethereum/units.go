@@ -0,0 +1,79 @@
+package ethereum
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EtherDecimals is the number of decimals between wei and ether
+const EtherDecimals = 18
+
+// WeiToEther formats a wei amount as a decimal ether string
+func WeiToEther(wei *big.Int) string {
+	return FormatUnits(wei, EtherDecimals)
+}
+
+// EtherToWei parses a decimal ether amount into wei
+func EtherToWei(ether string) (*big.Int, error) {
+	return ParseUnits(ether, EtherDecimals)
+}
+
+// unitDecimals maps the named denominations of ether to their decimals
+var unitDecimals = map[string]int{
+	"wei":   0,
+	"gwei":  9,
+	"ether": EtherDecimals,
+}
+
+// ParseAmount parses a decimal amount in the named unit (wei, gwei or ether) into wei.
+// An empty unit means wei.
+func ParseAmount(amount, unit string) (*big.Int, error) {
+	if unit == "" {
+		unit = "wei"
+	}
+	decimals, ok := unitDecimals[strings.ToLower(unit)]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q: must be wei, gwei or ether", unit)
+	}
+	return ParseUnits(amount, decimals)
+}
+
+// FormatUnits formats an integer amount with the given number of decimals,
+// trimming trailing zeros (e.g. 1500000000000000000 with 18 decimals is "1.5")
+func FormatUnits(value *big.Int, decimals int) string {
+	if value == nil {
+		return "0"
+	}
+
+	// 256 bits of precision keeps every decimal exact for realistic amounts
+	f := new(big.Float).SetPrec(256).SetInt(value)
+	f.Quo(f, new(big.Float).SetPrec(256).SetInt(pow10(decimals)))
+
+	text := f.Text('f', decimals)
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+	return text
+}
+
+// ParseUnits parses a decimal amount into an integer with the given number of decimals.
+// Amounts with more precision than the unit allows are rejected.
+func ParseUnits(amount string, decimals int) (*big.Int, error) {
+	// big.Rat parses decimal strings exactly, unlike binary floats
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(amount))
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt(pow10(decimals)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("amount %q has more than %d decimals", amount, decimals)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// pow10 returns 10^n
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
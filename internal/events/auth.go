@@ -0,0 +1,107 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// challengeMessage builds the text a client must sign to authenticate
+func challengeMessage(nonce string) string {
+	return "Sign this message to authenticate with go-web3: " + nonce
+}
+
+// sendChallenge sends a random nonce the client must sign to authenticate
+func (s *Service) sendChallenge(client *WebSocketClient) error {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return err
+	}
+	nonce := hexutil.Encode(buf)
+
+	client.stateMu.Lock()
+	client.challenge = nonce
+	client.stateMu.Unlock()
+
+	return client.SendJSON(map[string]interface{}{
+		"type":     "challenge",
+		"nonce":    nonce,
+		"message":  challengeMessage(nonce),
+		"required": s.config.RequireAuth,
+	})
+}
+
+// enforceAuth closes the client if it hasn't authenticated within the timeout
+func (s *Service) enforceAuth(client *WebSocketClient) {
+	timer := time.NewTimer(s.config.AuthTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		if _, ok := client.Address(); !ok {
+			s.logger.Info("Closing unauthenticated client", "client", client.ID)
+			client.SendJSON(map[string]interface{}{
+				"type":  "error",
+				"error": "authentication timeout",
+			})
+			client.SetCloseReason(CloseReasonAuthTimeout)
+			s.UnregisterClient(client.ID)
+		}
+	case <-client.Done():
+	}
+}
+
+// authenticate verifies the client's signature of its challenge and
+// associates the recovered address with the connection
+func (c *WebSocketClient) authenticate(signature string, claimed string) (common.Address, error) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if c.challenge == "" {
+		return common.Address{}, fmt.Errorf("no challenge issued")
+	}
+
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("malformed signature: %w", err)
+	}
+
+	address, err := ethereum.RecoverPersonalSigner([]byte(challengeMessage(c.challenge)), sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	if claimed != "" && (!common.IsHexAddress(claimed) || common.HexToAddress(claimed) != address) {
+		return common.Address{}, fmt.Errorf("signature does not match address %s", claimed)
+	}
+
+	// A challenge can only be used once
+	c.challenge = ""
+	c.address = &address
+	return address, nil
+}
+
+// Address returns the authenticated wallet address of the client, if any
+func (c *WebSocketClient) Address() (common.Address, bool) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	if c.address == nil {
+		return common.Address{}, false
+	}
+	return *c.address, true
+}
+
+// SendJSON marshals and sends a message to the client
+func (c *WebSocketClient) SendJSON(v interface{}) error {
+	message, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Send(message)
+}
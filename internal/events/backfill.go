@@ -0,0 +1,212 @@
+package events
+
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// backfillPageSize is the number of past logs fetched per page while backfilling
+const backfillPageSize = 500
+
+// backfill replays the past logs of a contract subscription to one client.
+// Live events of the subscription are held while the replay runs, then sent
+// after it, leaving out those the replay already delivered.
+type backfill struct {
+	contract common.Address
+	topics   [][]common.Hash
+	held     []heldEvent
+}
+
+// heldEvent is a live event held back during a backfill
+type heldEvent struct {
+	message []byte
+	log     types.Log
+}
+
+// covers reports whether a log belongs to the backfilled subscription
+func (b *backfill) covers(vLog types.Log) bool {
+	if vLog.Address != b.contract {
+		return false
+	}
+	for i, accepted := range b.topics {
+		if len(accepted) == 0 {
+			continue
+		}
+		if i >= len(vLog.Topics) || !slices.Contains(accepted, vLog.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// subscribeFrom subscribes the client to a contract's events and replays the
+// matching logs since fromBlock before switching to the live events. The live
+// subscription is made first so no log falls between the replay and it.
+func (c *WebSocketClient) subscribeFrom(service *Service, contract string, events []string, fromBlock uint64) error {
+	topics, err := contractTopics(events)
+	if err != nil {
+		return err
+	}
+
+	head, err := service.listener.client.GetLatestBlockNumber(c.ctx)
+	if err != nil {
+		return err
+	}
+	if fromBlock > head {
+		return fmt.Errorf("fromBlock %d is after the chain head %d", fromBlock, head)
+	}
+	if limit := service.config.MaxBackfillBlocks; limit > 0 && head-fromBlock >= uint64(limit) {
+		return fmt.Errorf("cannot backfill more than %d blocks", limit)
+	}
+
+	b := &backfill{contract: common.HexToAddress(contract), topics: topics}
+	c.backfillMu.Lock()
+	c.backfills = append(c.backfills, b)
+	c.backfillMu.Unlock()
+
+	if err := c.subscribe(service, contract, events); err != nil {
+		c.endBackfill(b, ethereum.LogPosition{})
+		return err
+	}
+
+	go c.runBackfill(service, b, fromBlock)
+	return nil
+}
+
+// runBackfill replays the logs of a backfill up to the chain head, reports
+// the outcome to the client and then releases the held live events
+func (c *WebSocketClient) runBackfill(service *Service, b *backfill, fromBlock uint64) {
+	sent, toBlock, covered, err := c.replayLogs(service, b, fromBlock)
+
+	c.backfillMu.Lock()
+	defer c.backfillMu.Unlock()
+
+	ack := map[string]interface{}{
+		"type":      "backfill",
+		"success":   err == nil,
+		"contract":  b.contract.Hex(),
+		"fromBlock": fromBlock,
+		"events":    sent,
+	}
+	if err != nil {
+		c.logger.Warn("Backfill failed", "contract", b.contract.Hex(), "error", err)
+		ack["error"] = err.Error()
+	} else {
+		ack["toBlock"] = toBlock
+	}
+	c.SendJSON(ack)
+
+	// Still holding backfillMu, so live events arriving meanwhile queue up behind the held ones
+	c.releaseHeld(b, covered)
+}
+
+// replayLogs sends the client the logs of a backfill from fromBlock to the
+// current head, waiting for room in its send buffer. It returns the number of
+// events sent, the last block of the replay and the position up to which logs
+// were delivered.
+func (c *WebSocketClient) replayLogs(service *Service, b *backfill, fromBlock uint64) (int, uint64, ethereum.LogPosition, error) {
+	var covered ethereum.LogPosition
+	toBlock, err := service.listener.client.GetLatestBlockNumber(c.ctx)
+	if err != nil {
+		return 0, 0, covered, err
+	}
+
+	query := ethereum.LogQuery{
+		Addresses: []common.Address{b.contract},
+		Topics:    b.topics,
+		FromBlock: fromBlock,
+		ToBlock:   toBlock,
+	}
+
+	sent := 0
+	var after *ethereum.LogPosition
+	for {
+		page, err := service.listener.client.GetLogsPage(c.ctx, query, after, backfillPageSize)
+		if err != nil {
+			return sent, toBlock, covered, err
+		}
+
+		for _, vLog := range page.Logs {
+			event := service.listener.contractEvent(vLog)
+			if !c.Accepts(event) {
+				continue
+			}
+			message, err := service.marshalEvent(event, 0)
+			if err != nil {
+				return sent, toBlock, covered, err
+			}
+			if err := c.sendWaiting(message); err != nil {
+				return sent, toBlock, covered, err
+			}
+			sent++
+		}
+
+		if page.Next == nil {
+			return sent, toBlock, ethereum.LogPosition{BlockNumber: toBlock, Index: math.MaxUint}, nil
+		}
+		covered = *page.Next
+		after = page.Next
+	}
+}
+
+// holdForBackfill holds a live event back if a running backfill covers it
+func (c *WebSocketClient) holdForBackfill(message []byte, event Event) bool {
+	vLog, ok := event.Data.(types.Log)
+	if !ok {
+		return false
+	}
+
+	c.backfillMu.Lock()
+	defer c.backfillMu.Unlock()
+
+	for _, b := range c.backfills {
+		if b.covers(vLog) {
+			b.held = append(b.held, heldEvent{message: message, log: vLog})
+			return true
+		}
+	}
+	return false
+}
+
+// endBackfill removes a backfill and releases its held events
+func (c *WebSocketClient) endBackfill(b *backfill, covered ethereum.LogPosition) {
+	c.backfillMu.Lock()
+	defer c.backfillMu.Unlock()
+
+	c.releaseHeld(b, covered)
+}
+
+// releaseHeld removes a backfill and sends its held events, except those at
+// or before the covered position, which the replay delivered already. Removed
+// logs of reorgs are always sent. The caller must hold backfillMu.
+func (c *WebSocketClient) releaseHeld(b *backfill, covered ethereum.LogPosition) {
+	c.backfills = slices.DeleteFunc(c.backfills, func(other *backfill) bool { return other == b })
+
+	for _, held := range b.held {
+		replayed := held.log.BlockNumber < covered.BlockNumber ||
+			(held.log.BlockNumber == covered.BlockNumber && held.log.Index <= covered.Index)
+		if replayed && !held.log.Removed {
+			continue
+		}
+		c.SendEvent(held.message)
+	}
+	b.held = nil
+}
+
+// sendWaiting queues a message like Send, but waits for room in the send
+// buffer rather than dropping a client that falls behind
+func (c *WebSocketClient) sendWaiting(message []byte) error {
+	select {
+	case c.send <- message:
+		c.messagesSent.Add(1)
+		clientMetrics.Add("messages_sent", 1)
+		return nil
+	case <-c.ctx.Done():
+		return fmt.Errorf("client connection closed")
+	}
+}
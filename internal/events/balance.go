@@ -0,0 +1,119 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// balanceWatch tracks the last known balance of an address watched by a client
+type balanceWatch struct {
+	balance   *big.Int // Nil until the first check
+	lastBlock uint64   // Last block the balance was checked at
+}
+
+// watchBalance starts watching the balance of an address, up to limit addresses per client
+func (c *WebSocketClient) watchBalance(address common.Address, balance *big.Int, limit int) error {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if _, ok := c.balances[address]; ok {
+		return nil
+	}
+	if len(c.balances) >= limit {
+		return fmt.Errorf("cannot watch more than %d addresses", limit)
+	}
+
+	if c.balances == nil {
+		c.balances = make(map[common.Address]*balanceWatch)
+	}
+	c.balances[address] = &balanceWatch{balance: balance}
+	return nil
+}
+
+// unwatchBalance stops watching the balance of an address
+func (c *WebSocketClient) unwatchBalance(address common.Address) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	delete(c.balances, address)
+}
+
+// watchedBalances returns the addresses whose balance the client watches
+func (c *WebSocketClient) watchedBalances() []common.Address {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	addresses := make([]common.Address, 0, len(c.balances))
+	for address := range c.balances {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// updateBalance records the balance of a watched address at a block. It returns
+// the previous balance and true if the balance changed since the last check.
+// Blocks at or below the last checked one are ignored, so each address is
+// checked at most once per block even when blocks are handled out of order.
+func (c *WebSocketClient) updateBalance(address common.Address, blockNumber uint64, balance *big.Int) (*big.Int, bool) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	watch, ok := c.balances[address]
+	if !ok || blockNumber <= watch.lastBlock {
+		return nil, false
+	}
+
+	previous := watch.balance
+	watch.balance = balance
+	watch.lastBlock = blockNumber
+	return previous, previous != nil && previous.Cmp(balance) != 0
+}
+
+// checkBalances fetches the balances of all watched addresses at a new block
+// and notifies the watching clients of those that changed
+func (s *Service) checkBalances(event Event) {
+	s.mu.RLock()
+	watchers := make(map[common.Address][]*WebSocketClient)
+	for _, client := range s.clients {
+		for _, address := range client.watchedBalances() {
+			watchers[address] = append(watchers[address], client)
+		}
+	}
+	s.mu.RUnlock()
+
+	for address, clients := range watchers {
+		// One balance lookup per address, however many clients watch it
+		balance, err := s.listener.client.GetBalanceAtBlock(s.listener.ctx, address, event.BlockNum)
+		if err != nil {
+			s.logger.Error("Error getting watched balance", "address", address.Hex(), "block", event.BlockNum, "error", err)
+			continue
+		}
+
+		for _, client := range clients {
+			previous, changed := client.updateBalance(address, event.BlockNum, balance)
+			if !changed {
+				continue
+			}
+
+			message, err := json.Marshal(map[string]interface{}{
+				"type":       "balance_change",
+				"address":    address.Hex(),
+				"oldBalance": ethereum.NewAmount(previous),
+				"newBalance": ethereum.NewAmount(balance),
+				"blockHash":  event.BlockHash.Hex(),
+				"blockNum":   event.BlockNum,
+			})
+			if err != nil {
+				s.logger.Error("Error marshaling balance change", "error", err)
+				continue
+			}
+			if err := client.SendEvent(message); err != nil {
+				s.logger.Warn("Error sending balance change to client", "client", client.ID, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"bytes"
+	"time"
+)
+
+// SendEvent sends a broadcast event to the client. Clients that opted into
+// batching receive events coalesced over the batch window as a single JSON
+// array frame, in the order they were broadcast.
+func (c *WebSocketClient) SendEvent(message []byte) error {
+	c.stateMu.RLock()
+	batching := c.filters.Batch
+	c.stateMu.RUnlock()
+
+	c.batchMu.Lock()
+	defer c.batchMu.Unlock()
+
+	if !batching || c.batchWindow <= 0 {
+		// Events batched before batching was turned off go first
+		c.sendBatch()
+		err := c.Send(message)
+		if err != nil {
+			c.deadLetters.Add(c.ID, message, err)
+		}
+		return err
+	}
+
+	c.batch = append(c.batch, message)
+	if len(c.batch) == 1 {
+		time.AfterFunc(c.batchWindow, func() {
+			c.flushBatch()
+		})
+	}
+	return nil
+}
+
+// flushBatch sends the pending batched events as one JSON array
+func (c *WebSocketClient) flushBatch() {
+	c.batchMu.Lock()
+	defer c.batchMu.Unlock()
+
+	c.sendBatch()
+}
+
+// sendBatch sends the pending batched events as one JSON array. The caller
+// holds batchMu, so no event can be sent ahead of the batch.
+func (c *WebSocketClient) sendBatch() {
+	pending := c.batch
+	c.batch = nil
+	if len(pending) == 0 {
+		return
+	}
+
+	var frame bytes.Buffer
+	frame.WriteByte('[')
+	frame.Write(bytes.Join(pending, []byte{','}))
+	frame.WriteByte(']')
+
+	if err := c.Send(frame.Bytes()); err != nil {
+		c.deadLetters.Add(c.ID, frame.Bytes(), err)
+		c.logger.Warn("Error sending event batch", "events", len(pending), "error", err)
+	}
+}
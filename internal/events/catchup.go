@@ -0,0 +1,117 @@
+package events
+
+import (
+	"expvar"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// handleHeader processes a new chain head, first catching up on any blocks
+// missed since the last processed one. If catching up fails, the head is
+// skipped so that the next header retries from the first block not processed.
+func (l *Listener) handleHeader(header *types.Header) {
+	number := header.Number.Uint64()
+	if next := l.nextBlock.Load(); next > 0 && number > next {
+		if err := l.catchUp(next, number-1); err != nil {
+			l.logger.Error("Error catching up on missed blocks", "from", next, "to", number-1, "error", err)
+			return
+		}
+	}
+
+	// Fetch the full block
+	block, err := l.client.BlockByHash(l.ctx, header.Hash())
+	if err != nil {
+		l.logger.Error("Error getting block", "hash", header.Hash().Hex(), "error", err)
+		return
+	}
+
+	l.processBlock(block)
+}
+
+// catchUp fetches blocks from..to (inclusive) in batched RPC calls, limited to
+// the configured number of batches per second, and processes them in order
+func (l *Listener) catchUp(from, to uint64) error {
+	batchSize := uint64(l.config.CatchUpBatchSize)
+	if batchSize == 0 {
+		batchSize = 1
+	}
+
+	var interval time.Duration
+	if l.config.CatchUpBatchesPerSecond > 0 {
+		interval = time.Second / time.Duration(l.config.CatchUpBatchesPerSecond)
+	}
+
+	l.logger.Info("Catching up on missed blocks", "from", from, "to", to)
+	defer l.catchUpRemaining.Store(0)
+
+	for start := from; start <= to; start += batchSize {
+		l.catchUpRemaining.Store(to - start + 1)
+
+		end := min(start+batchSize-1, to)
+		numbers := make([]uint64, 0, end-start+1)
+		for n := start; n <= end; n++ {
+			numbers = append(numbers, n)
+		}
+
+		batchStart := time.Now()
+		blocks, err := l.client.GetBlocksByNumber(l.ctx, numbers)
+		if err != nil {
+			return err
+		}
+		for _, block := range blocks {
+			l.processBlock(block)
+		}
+
+		// Stay within the provider's rate limit
+		if wait := interval - time.Since(batchStart); wait > 0 && end < to {
+			select {
+			case <-time.After(wait):
+			case <-l.ctx.Done():
+				return l.ctx.Err()
+			}
+		}
+	}
+
+	return nil
+}
+
+// CatchUpRemaining returns the number of blocks left to fetch while catching up
+func (l *Listener) CatchUpRemaining() uint64 {
+	return l.catchUpRemaining.Load()
+}
+
+// processBlock emits the block, transaction and (optionally) log events of a block
+func (l *Listener) processBlock(block *types.Block) {
+	// Create an event
+	event := Event{
+		Type:      EventTypeNewBlock,
+		BlockHash: block.Hash(),
+		BlockNum:  block.NumberU64(),
+		Data:      block,
+	}
+
+	// Notify handlers
+	l.notifyHandlers(event)
+
+	// Process transactions in the block
+	l.notifyTransactions(block)
+
+	// Emit every log of the block when capturing all logs
+	if l.config.CaptureAllLogs && l.enabled[EventTypeContractEvent] {
+		l.emitBlockLogs(block)
+	}
+
+	if l.tracing {
+		l.emitInternalTransactions(block)
+	}
+
+	l.nextBlock.Store(block.NumberU64() + 1)
+}
+
+// registerCatchUpMetrics exposes the catch-up progress
+func (l *Listener) registerCatchUpMetrics() {
+	listenerMetrics.Set("catchup_remaining", expvar.Func(func() interface{} {
+		return l.CatchUpRemaining()
+	}))
+}
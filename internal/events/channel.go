@@ -0,0 +1,82 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Channel is a dedicated WebSocket stream carrying a single kind of event
+type Channel struct {
+	Filters  EventFilters // Applied on top of the filters the client sets itself
+	Contract string       // Contract subscribed to on the client's behalf, if any
+}
+
+// BlocksChannel delivers new blocks only
+func BlocksChannel() Channel {
+	return Channel{
+		Filters: EventFilters{EventTypes: []EventType{EventTypeNewBlock}},
+	}
+}
+
+// TransactionsChannel delivers mined, pending, internal and high value transactions only
+func TransactionsChannel() Channel {
+	return Channel{
+		Filters: EventFilters{EventTypes: []EventType{
+			EventTypeNewTransaction,
+			EventTypePendingTransaction,
+			EventTypeInternalTransaction,
+			EventTypeHighValueTransaction,
+		}},
+	}
+}
+
+// ContractChannel delivers the events of a single contract, which the client
+// is subscribed to when it joins
+func ContractChannel(contract common.Address) Channel {
+	return Channel{
+		Filters: EventFilters{
+			EventTypes:      []EventType{EventTypeContractEvent},
+			ContractAddress: []string{contract.Hex()},
+		},
+		Contract: contract.Hex(),
+	}
+}
+
+// SetChannel restricts the client to the events of a channel. Filter messages
+// from the client can narrow the channel further but never widen it. It must
+// be called before the client is registered.
+func (c *WebSocketClient) SetChannel(channel Channel) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.channel = &channel
+}
+
+// joinChannel subscribes the client to its channel's contract, unless it has
+// no channel contract or a resumed session already subscribed to all of its events
+func (c *WebSocketClient) joinChannel(service *Service) {
+	c.stateMu.RLock()
+	channel := c.channel
+	subscribed := false
+	for _, subscription := range c.subscriptions {
+		if channel != nil && strings.EqualFold(subscription.Contract, channel.Contract) && len(subscription.Events) == 0 {
+			subscribed = true
+			break
+		}
+	}
+	c.stateMu.RUnlock()
+
+	if channel == nil || channel.Contract == "" || subscribed {
+		return
+	}
+
+	if err := c.subscribe(service, channel.Contract, nil); err != nil {
+		c.SendJSON(map[string]interface{}{
+			"type":     "subscribe",
+			"success":  false,
+			"contract": channel.Contract,
+			"error":    err.Error(),
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ContractEventData is the JSON shape of a contract event delivered to clients
+type ContractEventData struct {
+	Address     string                 `json:"address"`
+	BlockNumber uint64                 `json:"blockNumber"`
+	BlockHash   string                 `json:"blockHash"`
+	TxHash      string                 `json:"txHash"`
+	LogIndex    uint                   `json:"logIndex"`
+	Topics      []string               `json:"topics"`
+	Data        string                 `json:"data"`
+	Removed     bool                   `json:"removed,omitempty"`  // Set when the log was reverted by a reorg
+	Event       string                 `json:"event,omitempty"`    // Event name, when the contract's ABI is registered
+	Args        map[string]interface{} `json:"args,omitempty"`     // Decoded arguments, when the contract's ABI is registered
+	Transfer    *TokenTransfer         `json:"transfer,omitempty"` // Set for ERC20 Transfer events
+	Approval    *TokenApproval         `json:"approval,omitempty"` // Set for ERC20 Approval events
+}
+
+// newContractEventData converts a log to its client representation,
+// decoding it when the contract's ABI is registered or it is a standard
+// ERC20 event
+func newContractEventData(vLog types.Log, registry *ContractRegistry) ContractEventData {
+	topics := make([]string, len(vLog.Topics))
+	for i, topic := range vLog.Topics {
+		topics[i] = topic.Hex()
+	}
+
+	data := ContractEventData{
+		Address:     vLog.Address.Hex(),
+		BlockNumber: vLog.BlockNumber,
+		BlockHash:   vLog.BlockHash.Hex(),
+		TxHash:      vLog.TxHash.Hex(),
+		LogIndex:    vLog.Index,
+		Topics:      topics,
+		Data:        hexutil.Encode(vLog.Data),
+		Removed:     vLog.Removed,
+	}
+	if name, args, ok := registry.Decode(vLog); ok {
+		data.Event = name
+		data.Args = args
+	}
+	data.setTokenEvent(vLog)
+	return data
+}
@@ -0,0 +1,209 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// contractSubscription is a node log subscription shared by reference count
+type contractSubscription struct {
+	contract common.Address
+	topics   [][]common.Hash
+	sub      goethereum.Subscription
+	cancel   context.CancelFunc
+	refs     int
+}
+
+// ContractSubscriptionInfo describes an active node subscription for contract events
+type ContractSubscriptionInfo struct {
+	Contract common.Address  `json:"contract"`
+	Topics   [][]common.Hash `json:"topics"`
+	Refs     int             `json:"refs"` // Number of subscribers sharing it
+}
+
+// contractSubscriptionKey identifies a subscription by contract and topics
+func contractSubscriptionKey(contractAddress common.Address, topics [][]common.Hash) string {
+	var key strings.Builder
+	key.WriteString(contractAddress.Hex())
+	for _, position := range topics {
+		key.WriteByte('/')
+		for i, topic := range position {
+			if i > 0 {
+				key.WriteByte(',')
+			}
+			key.WriteString(topic.Hex())
+		}
+	}
+	return key.String()
+}
+
+// SubscribeToContractEvents subscribes to events from a specific contract.
+// Subscriptions with the same contract and topics share a single node
+// subscription; each call must be paired with UnsubscribeFromContractEvents
+// to release it.
+func (l *Listener) SubscribeToContractEvents(contractAddress common.Address, topics [][]common.Hash) error {
+	if !l.enabled[EventTypeContractEvent] {
+		return fmt.Errorf("contract events are disabled by events.eventTypes")
+	}
+
+	key := contractSubscriptionKey(contractAddress, topics)
+
+	l.contractSubsMu.Lock()
+	defer l.contractSubsMu.Unlock()
+
+	if existing, ok := l.contractSubs[key]; ok {
+		existing.refs++
+		return nil
+	}
+
+	query := goethereum.FilterQuery{
+		Addresses: []common.Address{contractAddress},
+		Topics:    topics,
+	}
+
+	logs := make(chan types.Log, l.subscriptionBuffer())
+	sub, err := l.client.SubscribeFilterLogs(l.ctx, query, logs)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(l.ctx)
+	entry := &contractSubscription{contract: contractAddress, topics: topics, sub: sub, cancel: cancel, refs: 1}
+	l.contractSubs[key] = entry
+
+	l.spawn(func() { l.forwardContractLogs(ctx, entry, query, sub, logs) })
+	return nil
+}
+
+// forwardContractLogs notifies handlers of the logs of a contract subscription
+// until it is released. A subscription dropped with the provider connection is
+// re-established once the node is reachable again.
+func (l *Listener) forwardContractLogs(ctx context.Context, entry *contractSubscription, query goethereum.FilterQuery, sub goethereum.Subscription, logs chan types.Log) {
+	contractAddress := query.Addresses[0]
+	for {
+		select {
+		case err := <-sub.Err():
+			if err != nil {
+				l.logger.Error("Error in contract event subscription", "contract", contractAddress.Hex(), "error", err)
+				l.spawn(func() {
+					l.resubscribe(ctx, "contract "+contractAddress.Hex(), func() error {
+						return l.restartContractSubscription(ctx, entry, query)
+					})
+				})
+			}
+			return
+		case vLog := <-logs:
+			l.notifyHandlers(l.contractEvent(vLog))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// contractEvent creates the contract event of a log
+func (l *Listener) contractEvent(vLog types.Log) Event {
+	return Event{
+		Type:      EventTypeContractEvent,
+		BlockHash: vLog.BlockHash,
+		BlockNum:  vLog.BlockNumber,
+		TxHash:    vLog.TxHash,
+		Name:      l.registry.EventName(vLog),
+		Data:      vLog,
+	}
+}
+
+// restartContractSubscription opens a new node subscription for a contract
+// subscription entry that is still in use
+func (l *Listener) restartContractSubscription(ctx context.Context, entry *contractSubscription, query goethereum.FilterQuery) error {
+	logs := make(chan types.Log, l.subscriptionBuffer())
+	sub, err := l.client.SubscribeFilterLogs(ctx, query, logs)
+	if err != nil {
+		return err
+	}
+
+	l.contractSubsMu.Lock()
+	defer l.contractSubsMu.Unlock()
+
+	if ctx.Err() != nil {
+		// Released while resubscribing
+		sub.Unsubscribe()
+		return nil
+	}
+	entry.sub = sub
+
+	l.spawn(func() { l.forwardContractLogs(ctx, entry, query, sub, logs) })
+	return nil
+}
+
+// UnsubscribeFromContractEvents releases a contract subscription, closing the
+// node subscription once nobody is interested in it anymore
+func (l *Listener) UnsubscribeFromContractEvents(contractAddress common.Address, topics [][]common.Hash) {
+	key := contractSubscriptionKey(contractAddress, topics)
+
+	l.contractSubsMu.Lock()
+	defer l.contractSubsMu.Unlock()
+
+	existing, ok := l.contractSubs[key]
+	if !ok {
+		return
+	}
+
+	existing.refs--
+	if existing.refs > 0 {
+		return
+	}
+
+	existing.cancel()
+	existing.sub.Unsubscribe()
+	delete(l.contractSubs, key)
+}
+
+// ContractSubscriptionCount returns the number of active node subscriptions for contract events
+func (l *Listener) ContractSubscriptionCount() int {
+	l.contractSubsMu.Lock()
+	defer l.contractSubsMu.Unlock()
+
+	return len(l.contractSubs)
+}
+
+// ContractSubscriptions returns the active node subscriptions for contract
+// events, ordered by contract and topics
+func (l *Listener) ContractSubscriptions() []ContractSubscriptionInfo {
+	l.contractSubsMu.Lock()
+	defer l.contractSubsMu.Unlock()
+
+	keys := make([]string, 0, len(l.contractSubs))
+	for key := range l.contractSubs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	infos := make([]ContractSubscriptionInfo, 0, len(keys))
+	for _, key := range keys {
+		entry := l.contractSubs[key]
+		topics := entry.topics
+		if topics == nil {
+			topics = [][]common.Hash{}
+		}
+		infos = append(infos, ContractSubscriptionInfo{Contract: entry.contract, Topics: topics, Refs: entry.refs})
+	}
+	return infos
+}
+
+// closeContractSubscriptions closes every contract subscription
+func (l *Listener) closeContractSubscriptions() {
+	l.contractSubsMu.Lock()
+	defer l.contractSubsMu.Unlock()
+
+	for key, existing := range l.contractSubs {
+		existing.cancel()
+		existing.sub.Unsubscribe()
+		delete(l.contractSubs, key)
+	}
+}
@@ -0,0 +1,75 @@
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// DeadLetter is an event that could not be delivered to a client
+type DeadLetter struct {
+	Time     time.Time       `json:"time"`
+	ClientID string          `json:"clientId"`
+	Error    string          `json:"error"`
+	Event    json.RawMessage `json:"event"`
+}
+
+// deadLetterLog keeps the most recent failed deliveries in a ring buffer.
+// A nil log records nothing.
+type deadLetterLog struct {
+	mu      sync.Mutex
+	entries []DeadLetter
+	next    int
+	full    bool
+}
+
+// newDeadLetterLog creates a log holding up to size entries, or nil if size is zero
+func newDeadLetterLog(size int) *deadLetterLog {
+	if size <= 0 {
+		return nil
+	}
+	return &deadLetterLog{entries: make([]DeadLetter, size)}
+}
+
+// Add records an event that failed to be delivered to a client
+func (d *deadLetterLog) Add(clientID string, event []byte, err error) {
+	clientMetrics.Add("dead_letters", 1)
+	if d == nil {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.entries[d.next] = DeadLetter{
+		Time:     time.Now(),
+		ClientID: clientID,
+		Error:    err.Error(),
+		Event:    json.RawMessage(event),
+	}
+	d.next++
+	if d.next == len(d.entries) {
+		d.next = 0
+		d.full = true
+	}
+}
+
+// Entries returns the recorded failed deliveries, oldest first
+func (d *deadLetterLog) Entries() []DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.full {
+		return append([]DeadLetter(nil), d.entries[:d.next]...)
+	}
+	return append(append([]DeadLetter(nil), d.entries[d.next:]...), d.entries[:d.next]...)
+}
+
+// DeadLetters returns the events that recently failed to be delivered to
+// clients, oldest first. It returns false if the dead-letter log is disabled.
+func (s *Service) DeadLetters() ([]DeadLetter, bool) {
+	if s.deadLetters == nil {
+		return nil, false
+	}
+	return s.deadLetters.Entries(), true
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Matches reports whether an event passes the client's filters.
+// Empty filters match everything.
+func (f EventFilters) Matches(event Event) bool {
+	if len(f.EventTypes) > 0 && !containsEventType(f.EventTypes, event.Type) {
+		return false
+	}
+
+	if event.Type != EventTypeContractEvent {
+		return true
+	}
+
+	vLog, ok := event.Data.(types.Log)
+	if !ok {
+		return true
+	}
+
+	if len(f.ContractAddress) > 0 && !containsAddress(f.ContractAddress, vLog.Address) {
+		return false
+	}
+
+	return matchTopics(f.Topics, vLog.Topics)
+}
+
+// matchTopics matches log topics by position, as eth_getLogs does: each
+// position lists the accepted topics and an empty position accepts any topic
+func matchTopics(filter [][]string, topics []common.Hash) bool {
+	for i, accepted := range filter {
+		if len(accepted) == 0 {
+			continue
+		}
+		if i >= len(topics) {
+			return false
+		}
+
+		found := false
+		for _, topic := range accepted {
+			if common.HexToHash(topic) == topics[i] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTopicFilter converts a decoded JSON topic filter into validated topic
+// hashes. Positions may be null, a single topic or a list of topics; malformed
+// entries are ignored.
+func parseTopicFilter(raw []interface{}) [][]string {
+	topics := make([][]string, len(raw))
+	for i, position := range raw {
+		switch v := position.(type) {
+		case string:
+			if isTopicHex(v) {
+				topics[i] = []string{v}
+			}
+		case []interface{}:
+			for _, entry := range v {
+				if topic, ok := entry.(string); ok && isTopicHex(topic) {
+					topics[i] = append(topics[i], topic)
+				}
+			}
+		}
+	}
+	return topics
+}
+
+// isTopicHex reports whether s is a 0x-prefixed 32-byte hex string
+func isTopicHex(s string) bool {
+	b, err := hexutil.Decode(s)
+	return err == nil && len(b) == common.HashLength
+}
+
+// containsEventType reports whether the event type is in the list
+func containsEventType(types []EventType, eventType EventType) bool {
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAddress reports whether the address is in the list, ignoring case
+func containsAddress(addresses []string, address common.Address) bool {
+	for _, addr := range addresses {
+		if strings.EqualFold(addr, address.Hex()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// headWatch tracks the highest block emitted by the listener and wakes up
+// waiters when a newer one arrives
+type headWatch struct {
+	mu      sync.Mutex
+	number  uint64
+	changed chan struct{} // Closed and replaced on each new head
+}
+
+// newHeadWatch creates a head watch that has seen no block yet
+func newHeadWatch() *headWatch {
+	return &headWatch{changed: make(chan struct{})}
+}
+
+// update records a new block, waking up waiters if it's the highest so far
+func (w *headWatch) update(number uint64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if number <= w.number {
+		return
+	}
+	w.number = number
+	close(w.changed)
+	w.changed = make(chan struct{})
+}
+
+// latest returns the highest block seen, or 0 before the first one
+func (w *headWatch) latest() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.number
+}
+
+// wait blocks until a block after since was emitted, returning its number,
+// or the context ends
+func (w *headWatch) wait(ctx context.Context, since uint64) (uint64, error) {
+	for {
+		w.mu.Lock()
+		number, changed := w.number, w.changed
+		w.mu.Unlock()
+
+		if number > since {
+			return number, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
@@ -2,13 +2,20 @@ package events
 
 import (
 	"context"
-	"log"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"github.com/ethereum/go-ethereum"
+	"github.com/em/go-web3/internal/config"
+	"github.com/em/go-web3/internal/ethereum"
+	goethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
 )
 
 // EventType defines the type of Ethereum event
@@ -21,40 +28,140 @@ const (
 	EventTypeNewTransaction EventType = "new_transaction"
 	// EventTypeContractEvent is triggered when a contract event is emitted
 	EventTypeContractEvent EventType = "contract_event"
+	// EventTypePendingTransaction is triggered when a transaction enters the mempool
+	EventTypePendingTransaction EventType = "pending_transaction"
+	// EventTypeInternalTransaction is triggered for each value transfer made by a contract in a new block
+	EventTypeInternalTransaction EventType = "internal_transaction"
 )
 
+// EventTypeHighValueTransaction is sent by the transaction monitor for
+// transactions above a watched value. It doesn't come from the listener.
+const EventTypeHighValueTransaction EventType = "high_value_transaction"
+
+// eventTypes lists every event type the listener produces
+var eventTypes = []EventType{
+	EventTypeNewBlock,
+	EventTypeNewTransaction,
+	EventTypeContractEvent,
+	EventTypePendingTransaction,
+	EventTypeInternalTransaction,
+}
+
+// parseEventTypes returns the set of event types named in the configuration.
+// An empty list enables every type.
+func parseEventTypes(names []string) (map[EventType]bool, error) {
+	enabled := make(map[EventType]bool, len(eventTypes))
+	if len(names) == 0 {
+		for _, eventType := range eventTypes {
+			enabled[eventType] = true
+		}
+		return enabled, nil
+	}
+
+	for _, name := range names {
+		eventType := EventType(name)
+		if !slices.Contains(eventTypes, eventType) {
+			return nil, fmt.Errorf("unknown event type %q in events.eventTypes", name)
+		}
+		enabled[eventType] = true
+	}
+	return enabled, nil
+}
+
 // Event represents an Ethereum event
 type Event struct {
 	Type      EventType
 	BlockHash common.Hash
 	BlockNum  uint64
 	TxHash    common.Hash
+	TxIndex   uint   // Position of the transaction in its block, for transaction events
+	Name      string // Resolved event name for contract events with a registered ABI, or ERC20 Transfer and Approval
 	Data      interface{}
 }
 
+// blockTransactionQueue is the number of a block's transactions queued for a
+// handler ahead of it; block processing waits for the handler beyond it
+const blockTransactionQueue = 256
+
+// blockDispatchQueue is the number of blocks whose transactions wait for the
+// dispatcher; the transactions of blocks beyond it are dropped
+const blockDispatchQueue = 64
+
+// Node subscription feeds
+const (
+	subscriptionNewBlocks           = "new blocks"
+	subscriptionPendingTransactions = "pending transactions"
+)
+
+// Pauses before retrying a failed resubscription, doubled after each failure
+const (
+	resubscribeDelay    = 5 * time.Second
+	resubscribeMaxDelay = time.Minute
+)
+
 // Handler defines a function that handles events
 type Handler func(event Event)
 
 // Listener listens for Ethereum events
 type Listener struct {
-	client        *ethclient.Client
+	client        *ethereum.Client
+	config        *config.EventsConfig
 	handlers      map[EventType][]Handler
-	subscriptions []ethereum.Subscription
+	subscriptions map[string]goethereum.Subscription // Node subscriptions by feed
+	registry      *ContractRegistry
+	pool          *WorkerPool
+	logger        *slog.Logger
 	mu            sync.RWMutex
-	ctx           context.Context
-	cancel        context.CancelFunc
+
+	// Blocks whose transactions wait to be delivered to the handlers
+	blockTxs chan *types.Block
+
+	// Block tracking for catching up on missed blocks
+	nextBlock        atomic.Uint64 // Next block number expected; 0 until the first block
+	catchUpRemaining atomic.Uint64
+
+	// Set when internal transactions are enabled and the node supports tracing
+	tracing bool
+
+	// Event types emitted by this deployment; others are never built
+	enabled map[EventType]bool
+
+	// Contract log subscriptions shared by everyone interested in them
+	contractSubs   map[string]*contractSubscription
+	contractSubsMu sync.Mutex
+
+	// Goroutines of the listener, waited for by Stop
+	wg sync.WaitGroup
+
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 // NewListener creates a new event listener
-func NewListener(client *ethclient.Client) *Listener {
+func NewListener(client *ethereum.Client, cfg *config.EventsConfig, logger *slog.Logger) *Listener {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Listener{
+	l := &Listener{
 		client:        client,
+		config:        cfg,
 		handlers:      make(map[EventType][]Handler),
-		subscriptions: []ethereum.Subscription{},
+		subscriptions: make(map[string]goethereum.Subscription),
+		registry:      NewContractRegistry(),
+		pool:          NewWorkerPool(cfg.Workers, cfg.QueueSize),
+		blockTxs:      make(chan *types.Block, blockDispatchQueue),
+		logger:        logger,
+		contractSubs:  make(map[string]*contractSubscription),
 		ctx:           ctx,
 		cancel:        cancel,
 	}
+
+	// Backfill from the configured start block on the first new head
+	l.nextBlock.Store(cfg.StartBlock)
+	l.registerCatchUpMetrics()
+	listenerMetrics.Set("contract_subscriptions", expvar.Func(func() interface{} {
+		return l.ContractSubscriptionCount()
+	}))
+
+	return l
 }
 
 // Subscribe adds a handler for a specific event type
@@ -86,7 +193,7 @@ func (l *Listener) SubscribeToNewTransactions(handler TransactionHandler) {
 
 		tx, ok := event.Data.(*types.Transaction)
 		if !ok {
-			log.Printf("Warning: Expected transaction data but got %T", event.Data)
+			l.logger.Warn("Expected transaction data", "type", fmt.Sprintf("%T", event.Data))
 			return
 		}
 
@@ -102,9 +209,47 @@ func (l *Listener) SubscribeToNewTransactions(handler TransactionHandler) {
 	l.handlers[EventTypeNewTransaction] = append(l.handlers[EventTypeNewTransaction], wrapperHandler)
 }
 
+// SubscribeToPendingTransactions adds a specialized handler for pending transaction events.
+// The block hash and number passed to the handler are always zero.
+func (l *Listener) SubscribeToPendingTransactions(handler TransactionHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	wrapperHandler := func(event Event) {
+		tx, ok := event.Data.(*types.Transaction)
+		if !ok {
+			l.logger.Warn("Expected transaction data", "type", fmt.Sprintf("%T", event.Data))
+			return
+		}
+
+		handler(tx, common.Hash{}, 0)
+	}
+
+	l.handlers[EventTypePendingTransaction] = append(l.handlers[EventTypePendingTransaction], wrapperHandler)
+}
+
 // Start begins listening for events
 func (l *Listener) Start() error {
-	log.Println("Starting Ethereum event listener")
+	l.logger.Info("Starting Ethereum event listener")
+
+	enabled, err := parseEventTypes(l.config.EventTypes)
+	if err != nil {
+		return err
+	}
+	l.enabled = enabled
+
+	// Start the handler workers
+	l.pool.Start(l.ctx)
+	l.spawn(l.dispatchTransactions)
+
+	// Internal transactions need a node with a tracing API
+	if l.config.InternalTransactions && l.EventTypeEnabled(EventTypeInternalTransaction) {
+		if err := l.client.DetectTracing(l.ctx); err != nil {
+			l.logger.Warn("Internal transaction tracking disabled", "error", err)
+		} else {
+			l.tracing = true
+		}
+	}
 
 	// Start listening for new blocks
 	if err := l.subscribeToNewBlocks(); err != nil {
@@ -114,122 +259,334 @@ func (l *Listener) Start() error {
 	return nil
 }
 
+// EventTypeEnabled reports whether events of a type are emitted
+func (l *Listener) EventTypeEnabled(eventType EventType) bool {
+	return l.enabled[eventType]
+}
+
 // Stop stops listening for events
 func (l *Listener) Stop() {
-	log.Println("Stopping Ethereum event listener")
+	l.logger.Info("Stopping Ethereum event listener")
 
 	// Cancel the context
 	l.cancel()
 
 	// Unsubscribe from all subscriptions
+	l.mu.RLock()
 	for _, sub := range l.subscriptions {
 		sub.Unsubscribe()
 	}
+	l.mu.RUnlock()
+	l.closeContractSubscriptions()
+
+	// Let subscription loops and running handlers finish
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		l.pool.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(l.config.ShutdownTimeout):
+		l.logger.Warn("Timed out waiting for event listener goroutines", "timeout", l.config.ShutdownTimeout)
+	}
+}
+
+// spawn runs fn on a goroutine that Stop waits for
+func (l *Listener) spawn(fn func()) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		fn()
+	}()
+}
+
+// subscriptionBuffer returns the capacity of the channels node subscriptions
+// deliver to. Buffering lets notifications queue up while the listener is
+// busy instead of stalling the subscription, which drops it when it falls
+// too far behind.
+func (l *Listener) subscriptionBuffer() int {
+	return max(l.config.SubscriptionBufferSize, 0)
 }
 
 // subscribeToNewBlocks subscribes to new block events
 func (l *Listener) subscribeToNewBlocks() error {
-	headers := make(chan *types.Header)
+	headers := make(chan *types.Header, l.subscriptionBuffer())
 	sub, err := l.client.SubscribeNewHead(l.ctx, headers)
 	if err != nil {
 		return err
 	}
 
-	l.subscriptions = append(l.subscriptions, sub)
+	l.addSubscription(subscriptionNewBlocks, sub)
 
-	go func() {
+	l.spawn(func() {
 		for {
 			select {
 			case err := <-sub.Err():
-				log.Printf("Error in block subscription: %v", err)
-				return
-			case header := <-headers:
-				// Fetch the full block
-				block, err := l.client.BlockByHash(l.ctx, header.Hash())
 				if err != nil {
-					log.Printf("Error getting block: %v", err)
-					continue
+					l.logger.Error("Error in block subscription", "error", err)
+					l.spawn(func() { l.resubscribe(l.ctx, subscriptionNewBlocks, l.subscribeToNewBlocks) })
 				}
+				return
+			case header := <-headers:
+				l.handleHeader(header)
+			case <-l.ctx.Done():
+				return
+			}
+		}
+	})
 
-				// Create an event
-				event := Event{
-					Type:      EventTypeNewBlock,
-					BlockHash: block.Hash(),
-					BlockNum:  block.NumberU64(),
-					Data:      block,
-				}
+	return nil
+}
+
+// StartPendingTransactions subscribes to the node's mempool feed.
+// Nodes that don't stream full transactions fall back to hash notifications.
+func (l *Listener) StartPendingTransactions() error {
+	geth := gethclient.New(l.client.Client.Client())
+
+	txs := make(chan *types.Transaction, l.subscriptionBuffer())
+	sub, err := geth.SubscribeFullPendingTransactions(l.ctx, txs)
+	if err != nil {
+		l.logger.Warn("Full pending transaction feed unavailable, falling back to hashes", "error", err)
+		return l.subscribeToPendingHashes(geth)
+	}
+
+	l.addSubscription(subscriptionPendingTransactions, sub)
 
-				// Notify handlers
-				l.notifyHandlers(event)
-
-				// Process transactions in the block
-				for _, tx := range block.Transactions() {
-					txEvent := Event{
-						Type:      EventTypeNewTransaction,
-						BlockHash: block.Hash(),
-						BlockNum:  block.NumberU64(),
-						TxHash:    tx.Hash(),
-						Data:      tx,
-					}
-					l.notifyHandlers(txEvent)
+	l.spawn(func() {
+		for {
+			select {
+			case err := <-sub.Err():
+				if err != nil {
+					l.logger.Error("Error in pending transaction subscription", "error", err)
+					l.spawn(func() { l.resubscribe(l.ctx, subscriptionPendingTransactions, l.StartPendingTransactions) })
 				}
+				return
+			case tx := <-txs:
+				l.notifyPendingTransaction(tx)
 			case <-l.ctx.Done():
 				return
 			}
 		}
-	}()
+	})
 
 	return nil
 }
 
-// SubscribeToContractEvents subscribes to events from a specific contract
-func (l *Listener) SubscribeToContractEvents(contractAddress common.Address, topics [][]common.Hash) error {
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{contractAddress},
-		Topics:    topics,
-	}
-
-	logs := make(chan types.Log)
-	sub, err := l.client.SubscribeFilterLogs(l.ctx, query, logs)
+// subscribeToPendingHashes subscribes to pending transaction hashes and fetches each transaction
+func (l *Listener) subscribeToPendingHashes(geth *gethclient.Client) error {
+	hashes := make(chan common.Hash, l.subscriptionBuffer())
+	sub, err := geth.SubscribePendingTransactions(l.ctx, hashes)
 	if err != nil {
 		return err
 	}
 
-	l.subscriptions = append(l.subscriptions, sub)
+	l.addSubscription(subscriptionPendingTransactions, sub)
 
-	go func() {
+	l.spawn(func() {
 		for {
 			select {
 			case err := <-sub.Err():
-				log.Printf("Error in contract event subscription: %v", err)
+				if err != nil {
+					l.logger.Error("Error in pending transaction subscription", "error", err)
+					l.spawn(func() {
+						l.resubscribe(l.ctx, subscriptionPendingTransactions, func() error {
+							return l.subscribeToPendingHashes(geth)
+						})
+					})
+				}
 				return
-			case vLog := <-logs:
-				// Create an event
-				event := Event{
-					Type:      EventTypeContractEvent,
-					BlockHash: vLog.BlockHash,
-					BlockNum:  vLog.BlockNumber,
-					TxHash:    vLog.TxHash,
-					Data:      vLog,
+			case hash := <-hashes:
+				tx, isPending, err := l.client.TransactionByHash(l.ctx, hash)
+				if err != nil || !isPending {
+					// Already mined or dropped from the pool
+					continue
 				}
-
-				// Notify handlers
-				l.notifyHandlers(event)
+				l.notifyPendingTransaction(tx)
 			case <-l.ctx.Done():
 				return
 			}
 		}
-	}()
+	})
 
 	return nil
 }
 
-// notifyHandlers notifies all handlers for a specific event type
+// addSubscription records the node subscription of a feed to close when the
+// listener stops, replacing the one it re-establishes
+func (l *Listener) addSubscription(feed string, sub goethereum.Subscription) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.subscriptions[feed] = sub
+}
+
+// resubscribe re-establishes a subscription dropped with the provider
+// connection: it waits for the node to be reachable again, then calls
+// subscribe, retrying with exponential backoff until it succeeds or ctx ends.
+// Blocks missed in the meantime are caught up on with the next new head.
+// Handlers stay registered throughout and receive the events of the new
+// subscription.
+func (l *Listener) resubscribe(ctx context.Context, name string, subscribe func() error) {
+	delay := resubscribeDelay
+	for {
+		if err := l.client.WaitForReconnect(ctx); err != nil {
+			return
+		}
+		err := subscribe()
+		if err == nil {
+			l.logger.Info("Subscription re-established", "subscription", name)
+			return
+		}
+		l.logger.Error("Error re-establishing subscription", "subscription", name, "error", err, "retryIn", delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, resubscribeMaxDelay)
+	}
+}
+
+// notifyPendingTransaction notifies handlers of a pending transaction
+func (l *Listener) notifyPendingTransaction(tx *types.Transaction) {
+	l.notifyHandlers(Event{
+		Type:   EventTypePendingTransaction,
+		TxHash: tx.Hash(),
+		Data:   tx,
+	})
+}
+
+// emitBlockLogs emits all logs of a block as contract events, in log index order
+func (l *Listener) emitBlockLogs(block *types.Block) {
+	logs, err := l.client.GetBlockLogs(l.ctx, block.Hash())
+	if err != nil {
+		l.logger.Error("Error getting block logs", "block", block.NumberU64(), "hash", block.Hash().Hex(), "error", err)
+		return
+	}
+
+	for _, vLog := range logs {
+		l.notifyHandlers(l.contractEvent(vLog))
+	}
+}
+
+// emitInternalTransactions traces a block and emits its internal value transfers
+func (l *Listener) emitInternalTransactions(block *types.Block) {
+	transfers, err := l.client.TraceBlock(l.ctx, block.NumberU64())
+	if err != nil {
+		l.logger.Error("Error tracing block", "block", block.NumberU64(), "error", err)
+		return
+	}
+
+	for _, transfer := range transfers {
+		l.notifyHandlers(Event{
+			Type:      EventTypeInternalTransaction,
+			BlockHash: block.Hash(),
+			BlockNum:  block.NumberU64(),
+			TxHash:    transfer.TxHash,
+			Data:      transfer,
+		})
+	}
+}
+
+// RegisterContractABI registers a contract ABI used to name contract events
+func (l *Listener) RegisterContractABI(contractAddress common.Address, abiJSON string) error {
+	return l.registry.Register(contractAddress, abiJSON)
+}
+
+// notifyHandlers queues all handlers for a specific event type on the worker pool.
+// It never blocks, so a slow handler can't stall the subscription goroutines;
+// events are dropped instead when the queue is saturated.
 func (l *Listener) notifyHandlers(event Event) {
+	if !l.enabled[event.Type] {
+		return
+	}
+
 	l.mu.RLock()
 	defer l.mu.RUnlock()
 
 	for _, handler := range l.handlers[event.Type] {
-		go handler(event)
+		if !l.pool.Submit(handler, event) {
+			l.logger.Warn("Handler queue full, dropping event", "type", event.Type, "txHash", event.TxHash.Hex())
+		}
+	}
+}
+
+// notifyTransactions queues the transactions of a block for the dispatcher.
+// It never blocks, so slow handlers can't stall the header subscription; the
+// block's transactions are dropped when blockDispatchQueue blocks are waiting.
+func (l *Listener) notifyTransactions(block *types.Block) {
+	if len(block.Transactions()) == 0 || !l.enabled[EventTypeNewTransaction] {
+		return
+	}
+
+	select {
+	case l.blockTxs <- block:
+	default:
+		listenerMetrics.Add("dropped_blocks", 1)
+		l.logger.Warn("Transaction dispatch queue full, dropping block transactions", "block", block.NumberU64())
+	}
+}
+
+// dispatchTransactions delivers the transactions of the queued blocks, in
+// block order, until the listener stops
+func (l *Listener) dispatchTransactions() {
+	for {
+		select {
+		case block := <-l.blockTxs:
+			l.deliverTransactions(block)
+		case <-l.ctx.Done():
+			return
+		}
+	}
+}
+
+// deliverTransactions delivers the transaction events of a block. Each
+// handler receives the transactions of a block in index order, one after
+// another, on a single worker through a channel of at most
+// blockTransactionQueue events. Rather than dropping a large block's
+// transactions, the dispatcher waits for a free worker slot and for the
+// handler to keep up, so the memory held stays bounded whatever the block size.
+func (l *Listener) deliverTransactions(block *types.Block) {
+	txs := block.Transactions()
+
+	// Don't hold the lock while waiting on slow handlers
+	l.mu.RLock()
+	handlers := append([]Handler(nil), l.handlers[EventTypeNewTransaction]...)
+	l.mu.RUnlock()
+
+	blockEvent := Event{
+		Type:      EventTypeNewTransaction,
+		BlockHash: block.Hash(),
+		BlockNum:  block.NumberU64(),
+	}
+	// Handlers are fed one at a time: a handler's job may only be waiting
+	// for the dispatcher, or workers could all block on each other's channels
+	for _, handler := range handlers {
+		events := make(chan Event, min(len(txs), blockTransactionQueue))
+		deliver := func(Event) {
+			for event := range events {
+				handler(event)
+			}
+		}
+		if !l.pool.SubmitWait(l.ctx, deliver, blockEvent) {
+			return
+		}
+
+		for i, tx := range txs {
+			event := blockEvent
+			event.TxHash = tx.Hash()
+			event.TxIndex = uint(i)
+			event.Data = tx
+			select {
+			case events <- event:
+			case <-l.ctx.Done():
+				close(events)
+				return
+			}
+		}
+		close(events)
 	}
 }
@@ -0,0 +1,170 @@
+package events
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/big"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/em/go-web3/internal/config"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// newTestListener creates a listener with a running worker pool and
+// transaction dispatcher, and no node
+func newTestListener(t *testing.T, workers, queueSize int) *Listener {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &Listener{
+		config:   &config.EventsConfig{ShutdownTimeout: time.Second},
+		handlers: make(map[EventType][]Handler),
+		pool:     NewWorkerPool(workers, queueSize),
+		blockTxs: make(chan *types.Block, blockDispatchQueue),
+		logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		enabled:  map[EventType]bool{EventTypeNewTransaction: true},
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+	l.pool.Start(ctx)
+	l.spawn(l.dispatchTransactions)
+	t.Cleanup(func() {
+		cancel()
+		l.wg.Wait()
+		l.pool.Wait()
+	})
+	return l
+}
+
+// testBlock creates a block numbered number with txCount transfers
+func testBlock(number int64, txCount int) *types.Block {
+	txs := make(types.Transactions, txCount)
+	for i := range txs {
+		txs[i] = types.NewTx(&types.LegacyTx{Nonce: uint64(i), Value: big.NewInt(number)})
+	}
+	header := &types.Header{Number: big.NewInt(number)}
+	return types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: txs})
+}
+
+func TestNotifyTransactionsOrderUnderLoad(t *testing.T) {
+	const (
+		handlers = 4
+		blocks   = 5
+		txCount  = 2000 // Well beyond blockTransactionQueue and the pool's queue
+	)
+	l := newTestListener(t, 2, 2)
+
+	var mu sync.Mutex
+	seen := make([]map[uint64][]uint, handlers) // Handler to block to TxIndex in delivery order
+	var wg sync.WaitGroup
+	wg.Add(handlers * blocks * txCount)
+	for h := range seen {
+		seen[h] = make(map[uint64][]uint)
+		l.handlers[EventTypeNewTransaction] = append(l.handlers[EventTypeNewTransaction], func(event Event) {
+			mu.Lock()
+			seen[h][event.BlockNum] = append(seen[h][event.BlockNum], event.TxIndex)
+			mu.Unlock()
+			wg.Done()
+		})
+	}
+
+	for n := int64(1); n <= blocks; n++ {
+		l.notifyTransactions(testBlock(n, txCount))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for every transaction to be delivered")
+	}
+
+	for h := range seen {
+		for n := uint64(1); n <= blocks; n++ {
+			indexes := seen[h][n]
+			if len(indexes) != txCount {
+				t.Fatalf("handler %d got %d transactions of block %d, want %d", h, len(indexes), n, txCount)
+			}
+			for i, index := range indexes {
+				if index != uint(i) {
+					t.Fatalf("handler %d got transaction %d of block %d at position %d", h, index, n, i)
+				}
+			}
+		}
+	}
+}
+
+func TestNotifyTransactionsDoesNotBlockOnSlowHandlers(t *testing.T) {
+	const (
+		workers = 4
+		blocks  = 20
+		txCount = 1000
+	)
+	l := newTestListener(t, workers, 16)
+
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(4 * blocks * txCount)
+	for range 4 {
+		l.handlers[EventTypeNewTransaction] = append(l.handlers[EventTypeNewTransaction], func(Event) {
+			<-release
+			wg.Done()
+		})
+	}
+
+	// Track the peak goroutine count while the blocks are delivered
+	baseline := runtime.NumGoroutine()
+	peak := baseline
+	sampled := make(chan struct{})
+	stopSampling := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		for {
+			peak = max(peak, runtime.NumGoroutine())
+			select {
+			case <-stopSampling:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+
+	// The header goroutine hands blocks over while every handler is stuck
+	start := time.Now()
+	for n := int64(1); n <= blocks; n++ {
+		l.notifyTransactions(testBlock(n, txCount))
+	}
+	elapsed := time.Since(start)
+	t.Logf("%d blocks handed over in %v", blocks, elapsed)
+	if elapsed > time.Second {
+		t.Errorf("notifyTransactions blocked for %v on stuck handlers", elapsed)
+	}
+
+	close(release)
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(30 * time.Second):
+		t.Fatal("timed out waiting for every transaction to be delivered")
+	}
+	close(stopSampling)
+	<-sampled
+
+	// The sampler and the waiter above are the only goroutines started here
+	t.Logf("peak goroutines: %d over a baseline of %d", peak, baseline)
+	if peak > baseline+2 {
+		t.Errorf("peak goroutines = %d, want at most %d", peak, baseline+2)
+	}
+}
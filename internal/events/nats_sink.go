@@ -0,0 +1,57 @@
+package events
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsFlushTimeout bounds the wait for buffered events when closing the NATS sink
+const natsFlushTimeout = 5 * time.Second
+
+// NATSSink publishes events to NATS, each on the subject <prefix>.<event type>
+type NATSSink struct {
+	conn          *nats.Conn
+	subjectPrefix string
+}
+
+// NewNATSSink connects to a NATS server. A server that is down doesn't fail
+// the connection: the client keeps reconnecting in the background and
+// buffers events meanwhile, up to the client's reconnect buffer size.
+func NewNATSSink(url, subjectPrefix string, logger *slog.Logger) (*NATSSink, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("go-web3 events"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from NATS", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.Info("Reconnected to NATS", "url", conn.ConnectedUrl())
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	return &NATSSink{conn: conn, subjectPrefix: subjectPrefix}, nil
+}
+
+// Publish publishes an event on the subject of its type
+func (s *NATSSink) Publish(eventType string, eventJSON []byte) error {
+	return s.conn.Publish(s.subjectPrefix+"."+eventType, eventJSON)
+}
+
+// Close flushes the buffered events and closes the connection
+func (s *NATSSink) Close() error {
+	defer s.conn.Close()
+
+	if !s.conn.IsConnected() {
+		return nil
+	}
+	return s.conn.FlushTimeout(natsFlushTimeout)
+}
@@ -0,0 +1,131 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// PauseState reports whether broadcasting is paused and what happened to the
+// events broadcast meanwhile
+type PauseState struct {
+	Paused  bool       `json:"paused"`
+	Since   *time.Time `json:"since,omitempty"`
+	Held    int        `json:"held"`    // Events waiting to be delivered on resume
+	Dropped uint64     `json:"dropped"` // Events discarded since the pause began
+}
+
+// pauseGate holds back broadcasts while the service is paused, keeping up to
+// size of them for delivery on resume and dropping the rest
+type pauseGate struct {
+	mu       sync.Mutex
+	size     int
+	paused   bool
+	draining bool // Held deliveries are being made after a resume
+	since    time.Time
+	held     []func()
+	dropped  uint64
+}
+
+// newPauseGate creates an open gate keeping up to size events while paused.
+// With a size of zero, events broadcast while paused are dropped.
+func newPauseGate(size int) *pauseGate {
+	return &pauseGate{size: max(size, 0)}
+}
+
+// hold keeps or drops a delivery while the gate is paused, reporting whether
+// it did. Deliveries made while the held ones are drained after a resume are
+// queued behind them, so that events stay in order. The delivery must be made
+// by the caller otherwise.
+func (g *pauseGate) hold(deliver func()) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.paused {
+		if g.draining {
+			g.held = append(g.held, deliver)
+			return true
+		}
+		return false
+	}
+	if len(g.held) < g.size {
+		g.held = append(g.held, deliver)
+	} else {
+		g.dropped++
+		clientMetrics.Add("events_dropped_paused", 1)
+	}
+	return true
+}
+
+// drain makes the held deliveries, including those queued meanwhile, until
+// none are left or the gate is paused again. Deliveries are made without the
+// lock, so broadcasters queue theirs rather than wait. Only one drain runs at
+// a time. The caller must hold the lock, which is held again on return.
+func (g *pauseGate) drain() {
+	if g.draining {
+		return
+	}
+	g.draining = true
+	for !g.paused && len(g.held) > 0 {
+		held := g.held
+		g.held = nil
+		g.mu.Unlock()
+		for _, deliver := range held {
+			deliver()
+		}
+		g.mu.Lock()
+	}
+	g.draining = false
+}
+
+// state returns a snapshot of the gate
+func (g *pauseGate) state() PauseState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := PauseState{Paused: g.paused, Held: len(g.held), Dropped: g.dropped}
+	if g.paused {
+		since := g.since
+		state.Since = &since
+	}
+	return state
+}
+
+// Pause stops broadcasting events to clients, streams and sinks. Node
+// subscriptions keep running: events are held for delivery on resume, up to
+// events.pauseBufferSize of them, and dropped beyond that.
+func (s *Service) Pause() PauseState {
+	s.pause.mu.Lock()
+	if !s.pause.paused {
+		s.pause.paused = true
+		s.pause.since = time.Now()
+		s.pause.dropped = 0
+		s.logger.Info("Event broadcasting paused", "bufferSize", s.pause.size)
+	}
+	s.pause.mu.Unlock()
+
+	return s.pause.state()
+}
+
+// Resume delivers the events held while paused, in the order they were
+// broadcast, and resumes live broadcasting. Broadcasts made meanwhile are
+// delivered after the held events.
+func (s *Service) Resume() PauseState {
+	s.pause.mu.Lock()
+	if s.pause.paused {
+		s.logger.Info("Event broadcasting resumed",
+			"held", len(s.pause.held),
+			"dropped", s.pause.dropped,
+			"pausedFor", time.Since(s.pause.since),
+		)
+		s.pause.paused = false
+		s.pause.drain()
+	}
+	s.pause.mu.Unlock()
+
+	return s.pause.state()
+}
+
+// PauseState returns whether broadcasting is paused
+func (s *Service) PauseState() PauseState {
+	return s.pause.state()
+}
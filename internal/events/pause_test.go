@@ -0,0 +1,64 @@
+package events
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResumeDoesNotBlockBroadcasts(t *testing.T) {
+	g := newPauseGate(10)
+	g.paused = true
+
+	var mu sync.Mutex
+	var order []int
+	deliver := func(n int) func() {
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, n)
+		}
+	}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	g.hold(func() {
+		close(started)
+		<-release
+		deliver(1)()
+	})
+	g.hold(deliver(2))
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.paused = false
+		g.drain()
+	}()
+	<-started
+
+	// A broadcast made while the held events are delivered is queued behind them
+	held := make(chan bool)
+	go func() { held <- g.hold(deliver(3)) }()
+	select {
+	case ok := <-held:
+		if !ok {
+			t.Fatal("broadcast during the drain was not queued behind the held events")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("broadcast blocked while held events were delivered")
+	}
+
+	close(release)
+	<-drained
+
+	if want := []int{1, 2, 3}; !slices.Equal(order, want) {
+		t.Errorf("delivery order = %v, want %v", order, want)
+	}
+	if g.hold(deliver(4)) {
+		t.Error("broadcast held after the drain ended")
+	}
+}
@@ -0,0 +1,270 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// maxAwaitedConfirmations bounds the pending transactions a client waits to
+// see mined; the oldest are forgotten beyond it
+const maxAwaitedConfirmations = 1000
+
+// pendingWatch delivers the pending transactions matching a filter to a client
+type pendingWatch struct {
+	id     string
+	filter *TransactionFilter
+}
+
+// pendingWatches holds a client's watches and the matched transactions whose
+// confirmation it is waiting for
+type pendingWatches struct {
+	watches  []pendingWatch
+	awaiting map[common.Hash]string // Transaction hash to watch id
+	order    []common.Hash
+}
+
+// watchPending starts delivering the pending transactions matching filter,
+// up to limit watches per client, and returns the watch id
+func (c *WebSocketClient) watchPending(filter *TransactionFilter, limit int) (string, error) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if len(c.pending.watches) >= limit {
+		return "", fmt.Errorf("cannot have more than %d pending transaction watches", limit)
+	}
+
+	id := uuid.New().String()
+	c.pending.watches = append(c.pending.watches, pendingWatch{id: id, filter: filter})
+	return id, nil
+}
+
+// unwatchPending removes the watch with the given id, or all watches when id
+// is empty, along with the confirmations they await. It returns the number
+// of watches removed.
+func (c *WebSocketClient) unwatchPending(id string) int {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	var kept []pendingWatch
+	for _, watch := range c.pending.watches {
+		if id == "" || watch.id == id {
+			continue
+		}
+		kept = append(kept, watch)
+	}
+	removed := len(c.pending.watches) - len(kept)
+	c.pending.watches = kept
+
+	var order []common.Hash
+	for _, hash := range c.pending.order {
+		if id == "" || c.pending.awaiting[hash] == id {
+			delete(c.pending.awaiting, hash)
+			continue
+		}
+		order = append(order, hash)
+	}
+	c.pending.order = order
+	return removed
+}
+
+// matchPending returns the id of the first watch matching a pending
+// transaction and starts awaiting its confirmation
+func (c *WebSocketClient) matchPending(info *TransactionInfo) (string, bool) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	for _, watch := range c.pending.watches {
+		if !watch.filter.Matches(info) {
+			continue
+		}
+
+		hash := info.Transaction.Hash()
+		if c.pending.awaiting == nil {
+			c.pending.awaiting = make(map[common.Hash]string)
+		}
+		if _, ok := c.pending.awaiting[hash]; !ok {
+			c.pending.order = append(c.pending.order, hash)
+			if len(c.pending.order) > maxAwaitedConfirmations {
+				delete(c.pending.awaiting, c.pending.order[0])
+				c.pending.order = c.pending.order[1:]
+			}
+		}
+		c.pending.awaiting[hash] = watch.id
+		return watch.id, true
+	}
+	return "", false
+}
+
+// confirmPending stops awaiting a mined transaction, returning the id of the
+// watch that matched it while pending
+func (c *WebSocketClient) confirmPending(hash common.Hash) (string, bool) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	id, ok := c.pending.awaiting[hash]
+	if !ok {
+		return "", false
+	}
+	delete(c.pending.awaiting, hash)
+	for i, awaited := range c.pending.order {
+		if awaited == hash {
+			c.pending.order = append(c.pending.order[:i], c.pending.order[i+1:]...)
+			break
+		}
+	}
+	return id, true
+}
+
+// watchesPending reports whether the client has pending transaction watches
+// or awaits confirmations
+func (c *WebSocketClient) watchesPending() bool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	return len(c.pending.watches) > 0 || len(c.pending.awaiting) > 0
+}
+
+// PendingTransactionsEnabled reports whether the mempool feed is running
+func (s *Service) PendingTransactionsEnabled() bool {
+	return s.config.PendingTransactions && s.listener.EventTypeEnabled(EventTypePendingTransaction)
+}
+
+// deliverPendingWatches sends a pending transaction to the clients whose
+// watches match it, and a mined one to the clients that got it while pending.
+// Watches are evaluated against every transaction, not only those passing
+// the transaction processor's filters. While broadcasting is paused the
+// transaction is held with the other events and matched on resume.
+func (s *Service) deliverPendingWatches(info *TransactionInfo) {
+	if s.pause.hold(func() { s.sendPendingWatches(info) }) {
+		return
+	}
+	s.sendPendingWatches(info)
+}
+
+// sendPendingWatches sends a transaction to the clients watching for it
+func (s *Service) sendPendingWatches(info *TransactionInfo) {
+	s.mu.RLock()
+	var clients []*WebSocketClient
+	for _, client := range s.clients {
+		if client.watchesPending() {
+			clients = append(clients, client)
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, client := range clients {
+		var payload map[string]interface{}
+		if info.IsPending {
+			id, ok := client.matchPending(info)
+			if !ok {
+				continue
+			}
+			payload = pendingMatchPayload(info, id)
+		} else {
+			id, ok := client.confirmPending(info.Transaction.Hash())
+			if !ok {
+				continue
+			}
+			payload = pendingConfirmedPayload(info, id)
+		}
+
+		message, err := json.Marshal(payload)
+		if err != nil {
+			s.logger.Error("Error marshaling watched transaction", "error", err)
+			continue
+		}
+		if err := client.SendEvent(message); err != nil {
+			s.logger.Warn("Error sending watched transaction to client", "client", client.ID, "error", err)
+		}
+	}
+}
+
+// pendingMatchPayload describes a pending transaction matching a watch
+func pendingMatchPayload(info *TransactionInfo, watchID string) map[string]interface{} {
+	payload := map[string]interface{}{
+		"type":     "pending_transaction_match",
+		"watchId":  watchID,
+		"hash":     info.Transaction.Hash().Hex(),
+		"from":     info.From.Hex(),
+		"value":    ethereum.NewAmount(info.Value),
+		"gasPrice": info.GasPrice.String(),
+		"nonce":    info.Transaction.Nonce(),
+	}
+	if info.IsContractCreation {
+		payload["contractAddress"] = info.ContractAddress.Hex()
+	} else {
+		payload["to"] = info.To.Hex()
+	}
+	return payload
+}
+
+// pendingConfirmedPayload describes the inclusion of a watched transaction in a block
+func pendingConfirmedPayload(info *TransactionInfo, watchID string) map[string]interface{} {
+	payload := map[string]interface{}{
+		"type":             "pending_transaction_confirmed",
+		"watchId":          watchID,
+		"hash":             info.Transaction.Hash().Hex(),
+		"blockHash":        info.BlockHash.Hex(),
+		"blockNum":         info.BlockNumber,
+		"transactionIndex": info.TransactionIndex,
+	}
+	if info.Receipt != nil {
+		payload["status"] = info.Receipt.Status
+		payload["gasUsed"] = info.Receipt.GasUsed
+	}
+	return payload
+}
+
+// parsePendingFilter reads the criteria of a watch_pending message. minValue
+// is in ETH, like the monitor endpoints.
+func parsePendingFilter(msg map[string]interface{}) (*TransactionFilter, error) {
+	filter := &TransactionFilter{}
+
+	addressField := func(name string) (*common.Address, error) {
+		s, ok := msg[name].(string)
+		if !ok || s == "" {
+			return nil, nil
+		}
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("invalid %s address", name)
+		}
+		address := common.HexToAddress(s)
+		return &address, nil
+	}
+
+	var err error
+	if filter.Address, err = addressField("address"); err != nil {
+		return nil, err
+	}
+	if filter.FromAddress, err = addressField("from"); err != nil {
+		return nil, err
+	}
+	if filter.ToAddress, err = addressField("to"); err != nil {
+		return nil, err
+	}
+
+	if minValue, ok := msg["minValue"].(string); ok && minValue != "" {
+		filter.MinValue, err = ethereum.EtherToWei(minValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minValue: %w", err)
+		}
+	}
+	if method, ok := msg["methodSignature"].(string); ok && method != "" {
+		if len(strings.TrimPrefix(method, "0x")) < 8 {
+			return nil, fmt.Errorf("methodSignature must be a 4-byte selector")
+		}
+		filter.MethodSignature = method
+	}
+	filter.OnlyContractTxs, _ = msg["onlyContractTxs"].(bool)
+	filter.OnlyContractCreations, _ = msg["onlyContractCreations"].(bool)
+
+	if *filter == (TransactionFilter{}) {
+		return nil, fmt.Errorf("at least one criterion is required")
+	}
+	return filter, nil
+}
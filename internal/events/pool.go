@@ -0,0 +1,104 @@
+package events
+
+import (
+	"context"
+	"expvar"
+	"sync"
+)
+
+// listenerMetrics exposes event dispatch counters
+var listenerMetrics = expvar.NewMap("events_listener")
+
+// handlerJob is a single handler invocation for an event
+type handlerJob struct {
+	handler Handler
+	event   Event
+}
+
+// WorkerPool executes event handlers on a fixed number of goroutines
+type WorkerPool struct {
+	jobs    chan handlerJob
+	workers int
+	wg      sync.WaitGroup
+}
+
+// NewWorkerPool creates a worker pool with the given number of workers and queue size
+func NewWorkerPool(workers, queueSize int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	pool := &WorkerPool{
+		jobs:    make(chan handlerJob, queueSize),
+		workers: workers,
+	}
+
+	listenerMetrics.Set("queue_depth", expvar.Func(func() interface{} {
+		return len(pool.jobs)
+	}))
+	listenerMetrics.Set("queue_capacity", expvar.Func(func() interface{} {
+		return cap(pool.jobs)
+	}))
+
+	return pool
+}
+
+// Start launches the workers, which run until the context is cancelled
+func (p *WorkerPool) Start(ctx context.Context) {
+	p.wg.Add(p.workers)
+	for i := 0; i < p.workers; i++ {
+		go func() {
+			defer p.wg.Done()
+			for {
+				select {
+				case job := <-p.jobs:
+					job.handler(job.event)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// Wait blocks until the workers have stopped, after the context given to Start is cancelled
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+}
+
+// Submit queues a handler invocation without blocking.
+// It returns false and drops the job when the queue is full.
+func (p *WorkerPool) Submit(handler Handler, event Event) bool {
+	select {
+	case p.jobs <- handlerJob{handler: handler, event: event}:
+		listenerMetrics.Add("dispatched_events", 1)
+		return true
+	default:
+		listenerMetrics.Add("dropped_events", 1)
+		return false
+	}
+}
+
+// SubmitWait queues a handler invocation, waiting for room in the queue when
+// it is full. It returns false when ctx ends first.
+func (p *WorkerPool) SubmitWait(ctx context.Context, handler Handler, event Event) bool {
+	job := handlerJob{handler: handler, event: event}
+	select {
+	case p.jobs <- job:
+		listenerMetrics.Add("dispatched_events", 1)
+		return true
+	default:
+	}
+
+	listenerMetrics.Add("backpressure_waits", 1)
+	select {
+	case p.jobs <- job:
+		listenerMetrics.Add("dispatched_events", 1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
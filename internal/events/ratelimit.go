@@ -0,0 +1,44 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// messageLimiter is a token bucket limiting the messages a client may send.
+// Bursts of up to one second's worth of messages are allowed.
+type messageLimiter struct {
+	rate   float64 // Messages per second; 0 or less is unlimited
+	tokens float64
+	last   time.Time
+	mu     sync.Mutex
+}
+
+// newMessageLimiter creates a limiter allowing perSecond messages per second
+func newMessageLimiter(perSecond int) *messageLimiter {
+	return &messageLimiter{
+		rate:   float64(perSecond),
+		tokens: float64(perSecond),
+		last:   time.Now(),
+	}
+}
+
+// Allow reports whether a message may be processed now, consuming a token if so
+func (l *messageLimiter) Allow() bool {
+	if l == nil || l.rate <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.rate, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// contractEvents indexes the events of a single contract ABI
+type contractEvents struct {
+	abi       abi.ABI
+	byTopic   map[common.Hash]*abi.Event
+	anonymous []*abi.Event
+}
+
+// ContractRegistry maps contract addresses to their ABI events so that
+// logs can be resolved to named events
+type ContractRegistry struct {
+	contracts map[common.Address]*contractEvents
+	mu        sync.RWMutex
+}
+
+// NewContractRegistry creates a new, empty contract registry
+func NewContractRegistry() *ContractRegistry {
+	return &ContractRegistry{
+		contracts: make(map[common.Address]*contractEvents),
+	}
+}
+
+// Register parses the ABI and indexes its events by topic0 for the given contract
+func (r *ContractRegistry) Register(address common.Address, abiJSON string) error {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return fmt.Errorf("invalid ABI: %w", err)
+	}
+
+	entry := &contractEvents{
+		abi:     parsed,
+		byTopic: make(map[common.Hash]*abi.Event),
+	}
+	for name := range parsed.Events {
+		event := parsed.Events[name]
+		if event.Anonymous {
+			// Anonymous events have no signature topic, keep them aside
+			entry.anonymous = append(entry.anonymous, &event)
+			continue
+		}
+		entry.byTopic[event.ID] = &event
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.contracts[address] = entry
+	return nil
+}
+
+// Lookup resolves the ABI event that produced the given log, if known
+func (r *ContractRegistry) Lookup(vLog types.Log) (*abi.Event, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.contracts[vLog.Address]
+	if !ok {
+		return nil, false
+	}
+
+	if len(vLog.Topics) > 0 {
+		if event, ok := entry.byTopic[vLog.Topics[0]]; ok {
+			return event, true
+		}
+	}
+
+	// Fall back to anonymous events, matching on the number of indexed inputs.
+	// If more than one anonymous event fits, the log is ambiguous and left unnamed.
+	var match *abi.Event
+	for _, event := range entry.anonymous {
+		if countIndexed(event.Inputs) != len(vLog.Topics) {
+			continue
+		}
+		if match != nil {
+			return nil, false
+		}
+		match = event
+	}
+
+	return match, match != nil
+}
+
+// EventName returns the resolved event name for a log, falling back to the
+// standard ERC20 events, or an empty string
+func (r *ContractRegistry) EventName(vLog types.Log) string {
+	if event, ok := r.Lookup(vLog); ok {
+		return event.Name
+	}
+	return tokenEventName(vLog)
+}
+
+// countIndexed returns the number of indexed arguments
+func countIndexed(args abi.Arguments) int {
+	n := 0
+	for _, arg := range args {
+		if arg.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+// Decode resolves the event of a log and decodes its arguments, if the contract's ABI is registered
+func (r *ContractRegistry) Decode(vLog types.Log) (string, map[string]interface{}, bool) {
+	event, ok := r.Lookup(vLog)
+	if !ok {
+		return "", nil, false
+	}
+
+	args, err := ethereum.DecodeEventArgs(*event, &vLog)
+	if err != nil {
+		// The log matches the event signature but not its encoding
+		return event.Name, nil, true
+	}
+	return event.Name, args, true
+}
@@ -0,0 +1,183 @@
+package events
+
+import (
+	"sync"
+)
+
+// eventLog numbers the broadcast events and keeps the most recent ones in a
+// ring buffer, so that reconnecting clients can catch up on what they missed
+type eventLog struct {
+	mu      sync.Mutex
+	seq     uint64 // Sequence number of the last event
+	entries []loggedEvent
+	next    int
+	full    bool
+}
+
+// loggedEvent is a broadcast event kept for replay
+type loggedEvent struct {
+	seq     uint64
+	message []byte
+	event   Event
+}
+
+// newEventLog creates a log keeping up to size events. With a size of zero,
+// events are numbered but not kept.
+func newEventLog(size int) *eventLog {
+	return &eventLog{entries: make([]loggedEvent, max(size, 0))}
+}
+
+// record assigns the next sequence number to an event, encodes the event with
+// it and keeps it for replay. Events are numbered in the order they are recorded.
+func (l *eventLog) record(event Event, marshal func(seq uint64) ([]byte, error)) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	message, err := marshal(l.seq + 1)
+	if err != nil {
+		return nil, err
+	}
+	l.seq++
+
+	if len(l.entries) > 0 {
+		l.entries[l.next] = loggedEvent{seq: l.seq, message: message, event: event}
+		l.next++
+		if l.next == len(l.entries) {
+			l.next = 0
+			l.full = true
+		}
+	}
+	return message, nil
+}
+
+// since returns the kept events numbered after seq, oldest first, and whether
+// some of the events after seq are missing because they were evicted. A seq
+// ahead of the log, e.g. from before a restart, is reported as truncated too.
+func (l *eventLog) since(seq uint64) ([]loggedEvent, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if seq >= l.seq {
+		return nil, seq > l.seq
+	}
+
+	var kept []loggedEvent
+	if l.full {
+		kept = append(kept, l.entries[l.next:]...)
+	}
+	kept = append(kept, l.entries[:l.next]...)
+
+	oldest := l.seq + 1 // Nothing kept
+	if len(kept) > 0 {
+		oldest = kept[0].seq
+	}
+	truncated := oldest > seq+1
+
+	for i, entry := range kept {
+		if entry.seq > seq {
+			return kept[i:], truncated
+		}
+	}
+	return nil, truncated
+}
+
+// heldReplayEvent is a live event held back while missed events are replayed
+type heldReplayEvent struct {
+	message []byte
+	event   Event
+}
+
+// SetReplaySince makes the client receive the buffered events numbered after
+// seq when it registers, before the live events. It must be called before the
+// client is registered.
+func (c *WebSocketClient) SetReplaySince(seq uint64) {
+	c.replayMu.Lock()
+	defer c.replayMu.Unlock()
+
+	c.replaySince = &seq
+}
+
+// startReplay begins replaying the missed events to a client that asked for
+// them, holding its live events until the replay is over. The caller must
+// hold the service lock, so that no event is broadcast between taking the
+// missed events and holding the live ones.
+func (s *Service) startReplay(client *WebSocketClient) {
+	client.replayMu.Lock()
+	defer client.replayMu.Unlock()
+
+	if client.replaySince == nil {
+		return
+	}
+	since := *client.replaySince
+
+	if s.config.RequireAuth {
+		client.SendJSON(map[string]interface{}{
+			"type":    "replay",
+			"success": false,
+			"error":   "replay is unavailable when authentication is required",
+		})
+		return
+	}
+
+	missed, truncated := s.replayLog.since(since)
+	client.replaying = true
+	go client.replay(missed, since, truncated)
+}
+
+// replay sends the missed events that pass the client's filters, then the
+// live events held meanwhile. Replayed events wait for room in the send
+// buffer rather than disconnecting the client.
+func (c *WebSocketClient) replay(missed []loggedEvent, since uint64, truncated bool) {
+	var messages [][]byte
+	for _, entry := range missed {
+		if c.Accepts(entry.event) {
+			messages = append(messages, entry.message)
+		}
+	}
+
+	c.SendJSON(map[string]interface{}{
+		"type":      "replay",
+		"success":   true,
+		"since":     since,
+		"events":    len(messages),
+		"truncated": truncated,
+	})
+
+	for _, message := range messages {
+		if err := c.sendWaiting(message); err != nil {
+			return
+		}
+		clientMetrics.Add("events_replayed", 1)
+	}
+
+	c.endReplay()
+}
+
+// holdForReplay holds back a live event while missed events are being
+// replayed to the client, reporting whether it did
+func (c *WebSocketClient) holdForReplay(message []byte, event Event) bool {
+	c.replayMu.Lock()
+	defer c.replayMu.Unlock()
+
+	if !c.replaying {
+		return false
+	}
+	c.replayHeld = append(c.replayHeld, heldReplayEvent{message: message, event: event})
+	return true
+}
+
+// endReplay sends the live events held during the replay and switches the
+// client back to live delivery
+func (c *WebSocketClient) endReplay() {
+	c.replayMu.Lock()
+	defer c.replayMu.Unlock()
+
+	for _, held := range c.replayHeld {
+		if c.holdForBackfill(held.message, held.event) {
+			continue
+		}
+		c.SendEvent(held.message)
+	}
+	c.replayHeld = nil
+	c.replaying = false
+}
@@ -0,0 +1,217 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/em/go-web3/internal/config"
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// fakeNode serves the eth methods the listener uses over WebSocket. Each
+// newHeads subscription is handed to the test through heads.
+type fakeNode struct {
+	mu     sync.Mutex
+	blocks map[common.Hash]*types.Block
+	head   uint64
+	heads  chan chan *types.Header
+}
+
+func (n *fakeNode) BlockNumber() hexutil.Uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return hexutil.Uint64(n.head)
+}
+
+func (n *fakeNode) GetBlockByHash(hash common.Hash, _ bool) (map[string]interface{}, error) {
+	n.mu.Lock()
+	block, ok := n.blocks[hash]
+	n.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	fields["transactions"] = block.Transactions()
+	fields["uncles"] = []common.Hash{}
+	return fields, nil
+}
+
+func (n *fakeNode) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+
+	headers := make(chan *types.Header)
+	go func() {
+		for {
+			select {
+			case header := <-headers:
+				notifier.Notify(sub.ID, header)
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	n.heads <- headers
+	return sub, nil
+}
+
+// addBlock stores a block of txCount signed transfers on top of the chain
+func (n *fakeNode) addBlock(t *testing.T, number uint64, txCount int) *types.Block {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+	txs := make(types.Transactions, txCount)
+	for i := range txs {
+		tx, err := types.SignNewTx(key, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			To:       &to,
+			Value:    big.NewInt(1),
+			Gas:      21000,
+			GasPrice: big.NewInt(1),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs[i] = tx
+	}
+
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Difficulty: common.Big0,
+		Time:       number,
+	}
+	block := types.NewBlock(header, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+
+	n.mu.Lock()
+	n.blocks[block.Hash()] = block
+	n.head = number
+	n.mu.Unlock()
+	return block
+}
+
+func TestTransactionsFlowAfterSubscriptionError(t *testing.T) {
+	node := &fakeNode{
+		blocks: make(map[common.Hash]*types.Block),
+		heads:  make(chan chan *types.Header, 1),
+	}
+
+	// Each connection is served by the current RPC server; stopping it drops
+	// the listener's connection and subscription
+	var current atomic.Pointer[rpc.Server]
+	newServer := func() *rpc.Server {
+		server := rpc.NewServer()
+		if err := server.RegisterName("eth", node); err != nil {
+			t.Fatal(err)
+		}
+		return server
+	}
+	current.Store(newServer())
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current.Load().WebsocketHandler([]string{"*"}).ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := ethereum.NewClient(&config.EthereumConfig{
+		Provider:            "ws" + strings.TrimPrefix(httpServer.URL, "http"),
+		ChainID:             1,
+		PrivateKey:          common.Bytes2Hex(crypto.FromECDSA(key)),
+		RetryAttempts:       1,
+		ReconnectMaxBackoff: 100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	listener := NewListener(client, &config.EventsConfig{
+		Workers:         2,
+		QueueSize:       16,
+		ShutdownTimeout: time.Second,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	received := make(chan *TransactionInfo, 16)
+	processor := NewTransactionProcessor(listener).OnTransaction(func(info *TransactionInfo) {
+		received <- info
+	})
+	processor.Start()
+	if err := listener.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Stop()
+
+	awaitSubscription := func() chan *types.Header {
+		t.Helper()
+		select {
+		case headers := <-node.heads:
+			return headers
+		case <-time.After(10 * time.Second):
+			t.Fatal("timed out waiting for a newHeads subscription")
+			return nil
+		}
+	}
+	awaitTransactions := func(block *types.Block) {
+		t.Helper()
+		for i, tx := range block.Transactions() {
+			select {
+			case info := <-received:
+				if info.Transaction.Hash() != tx.Hash() || info.BlockNumber != block.NumberU64() {
+					t.Fatalf("got transaction %s of block %d, want transaction %d of block %d",
+						info.Transaction.Hash().Hex(), info.BlockNumber, i, block.NumberU64())
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatalf("timed out waiting for transaction %d of block %d", i, block.NumberU64())
+			}
+		}
+	}
+
+	headers := awaitSubscription()
+	block := node.addBlock(t, 1, 3)
+	headers <- block.Header()
+	awaitTransactions(block)
+
+	// Drop the connection: the subscription fails and the listener resubscribes
+	dropped := current.Swap(newServer())
+	dropped.Stop()
+	headers = awaitSubscription()
+
+	block = node.addBlock(t, 2, 3)
+	headers <- block.Header()
+	awaitTransactions(block)
+}
@@ -1,31 +1,76 @@
 package events
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"math/big"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/em/go-web3/internal/config"
+	"github.com/em/go-web3/internal/ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 // Service manages event subscriptions and broadcasting
 type Service struct {
 	listener    *Listener
 	clients     map[string]*WebSocketClient
+	streams     map[string]*StreamSubscriber
 	txProcessor *TransactionProcessor
+	config      *config.EventsConfig
+	logger      *slog.Logger
 	mu          sync.RWMutex
+	sessions    map[string]*clientSession
+	sessionsMu  sync.Mutex
+	deadLetters *deadLetterLog
+	head        *headWatch
+	replayLog   *eventLog
+	deliverMu   sync.Mutex // Held from numbering an event until it is sent, so clients get events in sequence order
+	pause       *pauseGate
+	allowed     map[common.Address]bool // Contracts clients may subscribe to; nil allows all
+	sinks       []*sinkPublisher
+	sinksMu     sync.RWMutex
+	quit        chan struct{}
 }
 
 // NewService creates a new event service
-func NewService(ethClient *ethclient.Client) *Service {
-	listener := NewListener(ethClient)
+func NewService(ethClient *ethereum.Client, cfg *config.EventsConfig, logger *slog.Logger) *Service {
+	listener := NewListener(ethClient, cfg, logger)
+
+	var allowed map[common.Address]bool
+	if cfg.RestrictContracts && len(cfg.ContractAllowlist) > 0 {
+		allowed = make(map[common.Address]bool, len(cfg.ContractAllowlist))
+		for _, address := range cfg.ContractAllowlist {
+			if !common.IsHexAddress(address) {
+				logger.Warn("Ignoring invalid address in the contract allowlist", "address", address)
+				continue
+			}
+			allowed[common.HexToAddress(address)] = true
+		}
+	}
+
 	return &Service{
 		listener:    listener,
 		clients:     make(map[string]*WebSocketClient),
-		txProcessor: NewTransactionProcessor(listener),
+		streams:     make(map[string]*StreamSubscriber),
+		txProcessor: NewTransactionProcessor(listener).WithReceipts(cfg.TransactionReceipts),
+		config:      cfg,
+		logger:      logger,
+		sessions:    make(map[string]*clientSession),
+		deadLetters: newDeadLetterLog(cfg.DeadLetterSize),
+		head:        newHeadWatch(),
+		replayLog:   newEventLog(cfg.ReplayBufferSize),
+		pause:       newPauseGate(cfg.PauseBufferSize),
+		allowed:     allowed,
+		quit:        make(chan struct{}),
 	}
 }
 
@@ -39,11 +84,23 @@ func (s *Service) Start() error {
 	// Subscribe to different event types
 	s.setupSubscriptions()
 
+	// Expire abandoned resume tokens
+	go s.expireSessions()
+
+	// The mempool feed is opt-in as it is very high volume
+	if s.config.PendingTransactions && s.listener.EventTypeEnabled(EventTypePendingTransaction) {
+		if err := s.listener.StartPendingTransactions(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 // Stop stops the event service
 func (s *Service) Stop() {
+	close(s.quit)
+
 	// Stop the event listener
 	s.listener.Stop()
 
@@ -52,15 +109,47 @@ func (s *Service) Stop() {
 		s.txProcessor.Stop()
 	}
 
-	// Close all WebSocket connections
+	// End event streams and close all WebSocket connections
+	s.closeStreams()
+	s.closeSinks()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	for _, client := range s.clients {
+		client.SetCloseReason(CloseReasonServerShutdown)
 		client.Close()
 	}
 }
 
+// CloseClients gracefully closes all WebSocket clients, flushing queued events
+// and sending a close frame, until the context expires. Event streams are ended.
+func (s *Service) CloseClients(ctx context.Context) {
+	s.closeStreams()
+
+	s.mu.RLock()
+	clients := make([]*WebSocketClient, 0, len(s.clients))
+	for _, client := range s.clients {
+		clients = append(clients, client)
+	}
+	s.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(client *WebSocketClient) {
+			defer wg.Done()
+			client.SetCloseReason(CloseReasonServerShutdown)
+			client.Shutdown(ctx)
+		}(client)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		s.UnregisterClient(client.ID)
+	}
+}
+
 // setupSubscriptions sets up event handlers
 func (s *Service) setupSubscriptions() {
 	// Handle new blocks
@@ -68,6 +157,14 @@ func (s *Service) setupSubscriptions() {
 		s.broadcastEvent(event)
 	})
 
+	// Check watched balances on each new block
+	s.listener.Subscribe(EventTypeNewBlock, s.checkBalances)
+
+	// Wake up long-polling requests waiting for a new block
+	s.listener.Subscribe(EventTypeNewBlock, func(event Event) {
+		s.head.update(event.BlockNum)
+	})
+
 	// Handle new transactions
 	s.listener.Subscribe(EventTypeNewTransaction, func(event Event) {
 		s.broadcastEvent(event)
@@ -78,17 +175,19 @@ func (s *Service) setupSubscriptions() {
 		s.broadcastEvent(event)
 	})
 
+	// Handle internal transactions (only emitted when tracing is enabled)
+	s.listener.Subscribe(EventTypeInternalTransaction, func(event Event) {
+		s.broadcastEvent(event)
+	})
+
 	// Set up the transaction processor
 	s.txProcessor.OnTransaction(func(info *TransactionInfo) {
-		// Log high-value transactions
+		// Log high-value transactions at debug level
 		// Note: This is just an example - in a real application, you'd handle this differently
 		if info.Value.Cmp(big.NewInt(1000000000000000000)) > 0 { // > 1 ETH
-			log.Printf("High-value transaction detected: %s, Value: %s ETH",
-				info.Transaction.Hash().Hex(),
-				new(big.Float).Quo(
-					new(big.Float).SetInt(info.Value),
-					new(big.Float).SetInt(big.NewInt(1000000000000000000)),
-				).Text('f', 4),
+			s.logger.Debug("High-value transaction detected",
+				"hash", info.Transaction.Hash().Hex(),
+				"valueEth", ethereum.WeiToEther(info.Value),
 			)
 		}
 
@@ -97,48 +196,133 @@ func (s *Service) setupSubscriptions() {
 			"type":      "high_value_transaction",
 			"hash":      info.Transaction.Hash().Hex(),
 			"from":      info.From.Hex(),
-			"to":        info.To.Hex(),
-			"value":     info.Value.String(),
+			"value":     ethereum.NewAmount(info.Value),
 			"blockHash": info.BlockHash.Hex(),
+			"isPending": info.IsPending,
 		}
-
-		// Convert to JSON
-		eventJSON, err := json.Marshal(event)
-		if err == nil {
-			// Broadcast to interested clients
-			s.broadcastRawEvent(eventJSON)
+		if !info.IsPending {
+			event["transactionIndex"] = info.TransactionIndex
+		}
+		if info.Receipt != nil {
+			event["gasUsed"] = info.Receipt.GasUsed
+			event["status"] = info.Receipt.Status
+		}
+		if info.IsContractCreation {
+			event["isContractCreation"] = true
+			event["contractAddress"] = info.ContractAddress.Hex()
+		} else {
+			event["to"] = info.To.Hex()
 		}
+
+		// Broadcast to interested clients
+		s.broadcastRawEvent(EventTypeHighValueTransaction, event)
 	})
 
+	// Deliver pending transactions to the clients watching for them
+	s.txProcessor.OnAnyTransaction(s.deliverPendingWatches)
+
 	// Start the transaction processor
 	s.txProcessor.Start()
 }
 
-// SubscribeToContract subscribes to events from a specific contract
+// WaitForBlock blocks until the listener emits a block after since and returns
+// its number, or returns the context error once the context ends. Blocks
+// emitted while catching up count too.
+func (s *Service) WaitForBlock(ctx context.Context, since uint64) (uint64, error) {
+	return s.head.wait(ctx, since)
+}
+
+// SubscribeToContract subscribes to events from a specific contract. Each
+// event signature is either a canonical signature like Transfer(address,address,uint256)
+// or a 0x-prefixed topic hash; invalid ones fail with ErrInvalidEventSignature.
+// Contracts outside the configured allowlist fail with ErrContractNotAllowed.
+// Identical subscriptions share one node subscription until released with UnsubscribeFromContract.
 func (s *Service) SubscribeToContract(contractAddress string, eventSignatures []string) error {
-	address := common.HexToAddress(contractAddress)
+	if !s.ContractAllowed(contractAddress) {
+		return fmt.Errorf("%w: %s", ErrContractNotAllowed, common.HexToAddress(contractAddress).Hex())
+	}
+	topics, err := contractTopics(eventSignatures)
+	if err != nil {
+		return err
+	}
+	return s.listener.SubscribeToContractEvents(common.HexToAddress(contractAddress), topics)
+}
 
-	// Convert event signatures to topic hashes if needed
-	var topics [][]common.Hash
-	if len(eventSignatures) > 0 {
-		// This is a simplified example - in a real app, you would create proper topic filters
-		topicSet := []common.Hash{}
-		for _, sig := range eventSignatures {
-			// In reality, you would hash the signature: keccak256(EventName(type1,type2))
-			// For simplicity, we're just converting the string to a hash
-			topicSet = append(topicSet, common.HexToHash(sig))
-		}
-		topics = [][]common.Hash{topicSet}
+// UnsubscribeFromContract releases a subscription made with SubscribeToContract
+func (s *Service) UnsubscribeFromContract(contractAddress string, eventSignatures []string) {
+	topics, err := contractTopics(eventSignatures)
+	if err != nil {
+		// Invalid signatures were never subscribed
+		return
 	}
+	s.listener.UnsubscribeFromContractEvents(common.HexToAddress(contractAddress), topics)
+}
+
+// ErrContractNotAllowed is returned for subscriptions to contracts outside the allowlist
+var ErrContractNotAllowed = errors.New("contract is not in the subscription allowlist")
 
-	return s.listener.SubscribeToContractEvents(address, topics)
+// ContractAllowed reports whether clients may subscribe to a contract's
+// events. All contracts are allowed unless events.restrictContracts is set
+// with a non-empty events.contractAllowlist.
+func (s *Service) ContractAllowed(contractAddress string) bool {
+	return s.allowed == nil || s.allowed[common.HexToAddress(contractAddress)]
 }
 
-// AddTransactionFilter adds a filter for specific transaction types
-func (s *Service) AddTransactionFilter(filter *TransactionFilter) {
-	if s.txProcessor != nil {
-		s.txProcessor.WithFilter(filter)
+// ErrInvalidEventSignature is returned for event signatures that are neither
+// canonical signatures nor topic hashes
+var ErrInvalidEventSignature = errors.New("invalid event signatures")
+
+// contractTopics converts event signatures and topic hashes to a topic filter
+// matching any of them as the first topic
+func contractTopics(eventSignatures []string) ([][]common.Hash, error) {
+	if len(eventSignatures) == 0 {
+		return nil, nil
+	}
+
+	topicSet := make([]common.Hash, 0, len(eventSignatures))
+	var invalid []string
+	for _, sig := range eventSignatures {
+		if strings.HasPrefix(sig, "0x") {
+			raw, err := hexutil.Decode(sig)
+			if err != nil || len(raw) != common.HashLength {
+				invalid = append(invalid, strconv.Quote(sig))
+				continue
+			}
+			topicSet = append(topicSet, common.BytesToHash(raw))
+			continue
+		}
+
+		topic, err := ethereum.EventTopic(sig)
+		if err != nil {
+			invalid = append(invalid, strconv.Quote(sig))
+			continue
+		}
+		topicSet = append(topicSet, topic)
 	}
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEventSignature, strings.Join(invalid, ", "))
+	}
+	return [][]common.Hash{topicSet}, nil
+}
+
+// RegisterContractABI registers the ABI of a contract so its events are delivered by name
+func (s *Service) RegisterContractABI(contractAddress string, abiJSON string) error {
+	return s.listener.RegisterContractABI(common.HexToAddress(contractAddress), abiJSON)
+}
+
+// AddTransactionFilter adds a filter for specific transaction types and returns its id
+func (s *Service) AddTransactionFilter(filter *TransactionFilter) string {
+	return s.txProcessor.AddFilter(filter)
+}
+
+// RemoveTransactionFilter removes a transaction filter by id, reporting whether it existed
+func (s *Service) RemoveTransactionFilter(id string) bool {
+	return s.txProcessor.RemoveFilter(id)
+}
+
+// TransactionFilters returns the active transaction filters
+func (s *Service) TransactionFilters() []FilterEntry {
+	return s.txProcessor.Filters()
 }
 
 // AddTransactionHandler adds a custom handler for transaction events
@@ -148,19 +332,69 @@ func (s *Service) AddTransactionHandler(handler TransactionHandlerFunc) {
 	}
 }
 
-// WatchAddress creates a filter to watch transactions involving a specific address
-func (s *Service) WatchAddress(address string) {
+// WatchContractCreations creates a filter to watch contract deployments and returns its id
+func (s *Service) WatchContractCreations() string {
+	return s.AddTransactionFilter(&TransactionFilter{
+		OnlyContractCreations: true,
+	})
+}
+
+// WatchAddress creates a filter to watch transactions involving a specific address and returns its id
+func (s *Service) WatchAddress(address string) string {
 	addr := common.HexToAddress(address)
 	filter := &TransactionFilter{
-		FromAddress: &addr,
+		Address: &addr,
+	}
+	return s.AddTransactionFilter(filter)
+}
+
+// ReceiptsEnabled reports whether mined transactions are processed with their
+// receipts, which filters on the receipt status need
+func (s *Service) ReceiptsEnabled() bool {
+	return s.config.TransactionReceipts
+}
+
+// WatchAddresses watches transactions involving each of the given addresses.
+// Invalid addresses are logged and skipped.
+func (s *Service) WatchAddresses(addresses []string) {
+	for _, address := range addresses {
+		if !common.IsHexAddress(address) {
+			s.logger.Warn("Skipping invalid watch address", "address", address)
+			continue
+		}
+		id := s.WatchAddress(address)
+		s.logger.Info("Watching address", "address", common.HexToAddress(address).Hex(), "id", id)
+	}
+}
+
+// RegisterClient registers a new WebSocket client. If resumeToken refers to a
+// recently disconnected session, its filters and subscriptions are restored.
+func (s *Service) RegisterClient(client *WebSocketClient, resumeToken string) {
+	client.batchWindow = s.config.BatchWindow
+	client.deadLetters = s.deadLetters
+	client.limiter = newMessageLimiter(s.config.MaxMessagesPerSecond)
+	client.maxSubscriptions = s.config.MaxSubscriptions
+	client.unregister = func() { s.UnregisterClient(client.ID) }
+
+	resumed := resumeToken != "" && s.resumeClient(client, resumeToken)
+	s.openSession(client, resumed)
+	if !s.config.RequireAuth {
+		client.joinChannel(s)
+	}
+
+	// Offer wallet authentication, closing the client after a timeout if it is required
+	if err := s.sendChallenge(client); err != nil {
+		s.logger.Warn("Error sending challenge to client", "client", client.ID, "error", err)
 	}
-	s.AddTransactionFilter(filter)
-} // RegisterClient registers a new WebSocket client
-func (s *Service) RegisterClient(client *WebSocketClient) {
+	if s.config.RequireAuth {
+		go s.enforceAuth(client)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.clients[client.ID] = client
+	s.startReplay(client)
 
 	// Set up a ping/pong to keep the connection alive
 	go func() {
@@ -171,7 +405,9 @@ func (s *Service) RegisterClient(client *WebSocketClient) {
 			select {
 			case <-ticker.C:
 				if err := client.SendPing(); err != nil {
-					log.Printf("Error sending ping to client %s: %v", client.ID, err)
+					s.logger.Warn("Error sending ping to client", "client", client.ID, "error", err)
+					clientMetrics.Add("closed_ping_failure", 1)
+					client.SetCloseReason(CloseReasonPingFailed)
 					s.UnregisterClient(client.ID)
 					return
 				}
@@ -190,38 +426,133 @@ func (s *Service) UnregisterClient(clientID string) {
 	if client, ok := s.clients[clientID]; ok {
 		client.Close()
 		delete(s.clients, clientID)
+		s.closeSession(client)
+
+		clientMetrics.Add("disconnects", 1)
+		if s.config.LogDisconnects {
+			s.logger.Info("WebSocket client disconnected",
+				"client", client.ID,
+				"reason", client.CloseReason(),
+				"messagesSent", client.MessagesSent(),
+				"connectedFor", time.Since(client.connectedAt).Round(time.Second))
+		}
 	}
 }
 
-// broadcastEvent broadcasts an event to all connected WebSocket clients
+// broadcastEvent broadcasts an event to all connected WebSocket clients,
+// unless broadcasting is paused
 func (s *Service) broadcastEvent(event Event) {
+	if s.pause.hold(func() { s.deliverEvent(event) }) {
+		return
+	}
+	s.deliverEvent(event)
+}
+
+// deliverEvent numbers an event and sends it to the clients, streams and sinks
+func (s *Service) deliverEvent(event Event) {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	// Convert event to JSON
-	eventJSON, err := json.Marshal(map[string]interface{}{
+	eventJSON, err := s.replayLog.record(event, func(seq uint64) ([]byte, error) {
+		return s.marshalEvent(event, seq)
+	})
+	if err != nil {
+		s.logger.Error("Error marshaling event", "error", err)
+		return
+	}
+
+	// Broadcast to the clients whose filters match
+	s.sendToClients(eventJSON, event)
+	s.publishToSinks(string(event.Type), eventJSON)
+}
+
+// marshalEvent encodes an event as sent to clients, with its sequence number
+// unless seq is 0, as for past events that were never broadcast
+func (s *Service) marshalEvent(event Event, seq uint64) ([]byte, error) {
+	payload := map[string]interface{}{
 		"type":      event.Type,
 		"blockHash": event.BlockHash.Hex(),
 		"blockNum":  event.BlockNum,
 		"txHash":    event.TxHash.Hex(),
 		"data":      event.Data,
+	}
+	if seq > 0 {
+		payload["seq"] = seq
+	}
+	if event.Name != "" {
+		payload["event"] = event.Name
+	}
+	if event.Type == EventTypeNewTransaction {
+		payload["txIndex"] = event.TxIndex
+	}
+	if vLog, ok := event.Data.(types.Log); ok {
+		payload["data"] = newContractEventData(vLog, s.listener.registry)
+	}
+
+	return json.Marshal(payload)
+}
+
+// broadcastRawEvent broadcasts an event that isn't a chain event to the
+// connected WebSocket clients whose filters accept its type, unless
+// broadcasting is paused. Its payload gets a sequence number.
+func (s *Service) broadcastRawEvent(eventType EventType, payload map[string]interface{}) {
+	if s.pause.hold(func() { s.deliverRawEvent(eventType, payload) }) {
+		return
+	}
+	s.deliverRawEvent(eventType, payload)
+}
+
+// deliverRawEvent numbers a raw event and sends it to the clients, streams and sinks
+func (s *Service) deliverRawEvent(eventType EventType, payload map[string]interface{}) {
+	s.deliverMu.Lock()
+	defer s.deliverMu.Unlock()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	event := Event{Type: eventType, Data: payload}
+	eventJSON, err := s.replayLog.record(event, func(seq uint64) ([]byte, error) {
+		payload["seq"] = seq
+		return json.Marshal(payload)
 	})
 	if err != nil {
-		log.Printf("Error marshaling event: %v", err)
+		s.logger.Error("Error marshaling event", "error", err)
 		return
 	}
 
-	// Broadcast to all clients
-	s.broadcastRawEvent(eventJSON)
+	s.sendToClients(eventJSON, event)
+	s.publishToSinks(string(eventType), eventJSON)
 }
 
-// broadcastRawEvent broadcasts a raw JSON event to all connected WebSocket clients
-func (s *Service) broadcastRawEvent(eventJSON []byte) {
-	// Broadcast to all clients
+// sendToClients sends a JSON event to the connected clients and streams whose
+// filters accept the event
+func (s *Service) sendToClients(eventJSON []byte, event Event) {
+	for _, stream := range s.streams {
+		if stream.filters.Matches(event) {
+			stream.send(eventJSON)
+		}
+	}
+
 	for _, client := range s.clients {
-		err := client.Send(eventJSON)
+		if !client.Accepts(event) {
+			continue
+		}
+		if s.config.RequireAuth {
+			if _, ok := client.Address(); !ok {
+				continue
+			}
+		}
+		if client.holdForReplay(eventJSON, event) {
+			continue
+		}
+		if client.holdForBackfill(eventJSON, event) {
+			continue
+		}
+
+		err := client.SendEvent(eventJSON)
 		if err != nil {
-			log.Printf("Error sending event to client %s: %v", client.ID, err)
+			s.logger.Warn("Error sending event to client", "client", client.ID, "error", err)
 			// Don't unregister here to avoid deadlock, let the ping/pong handle it
 		}
 	}
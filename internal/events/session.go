@@ -0,0 +1,155 @@
+package events
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ContractSubscription records a contract subscription made by a client
+type ContractSubscription struct {
+	Contract string   `json:"contract"`
+	Events   []string `json:"events,omitempty"`
+}
+
+// subscribe subscribes the client to a contract's events, up to the
+// client's subscription limit
+func (c *WebSocketClient) subscribe(service *Service, contract string, events []string) error {
+	c.stateMu.RLock()
+	count := len(c.subscriptions)
+	c.stateMu.RUnlock()
+
+	if c.maxSubscriptions > 0 && count >= c.maxSubscriptions {
+		clientMetrics.Add("subscriptions_rejected", 1)
+		return fmt.Errorf("cannot have more than %d subscriptions", c.maxSubscriptions)
+	}
+
+	if err := service.SubscribeToContract(contract, events); err != nil {
+		return err
+	}
+
+	c.stateMu.Lock()
+	c.subscriptions = append(c.subscriptions, ContractSubscription{
+		Contract: contract,
+		Events:   events,
+	})
+	c.stateMu.Unlock()
+	return nil
+}
+
+// removeSubscriptions removes the client's subscriptions to a contract and
+// returns them. When matchEvents is set, only the subscription to exactly
+// these events is removed.
+func (c *WebSocketClient) removeSubscriptions(contract string, events []string, matchEvents bool) []ContractSubscription {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	var removed []ContractSubscription
+	var kept []ContractSubscription
+	for _, subscription := range c.subscriptions {
+		matches := strings.EqualFold(subscription.Contract, contract) &&
+			(!matchEvents || slices.Equal(subscription.Events, events))
+		if matches && (!matchEvents || len(removed) == 0) {
+			removed = append(removed, subscription)
+			continue
+		}
+		kept = append(kept, subscription)
+	}
+	c.subscriptions = kept
+	return removed
+}
+
+// clientSession is the subscription state kept for a resume token
+type clientSession struct {
+	filters       EventFilters
+	subscriptions []ContractSubscription
+	expiresAt     time.Time // Zero while a client is connected with the token
+}
+
+// resumeClient restores the state of a previous session into the client.
+// It returns false if the token is unknown, expired or already in use.
+func (s *Service) resumeClient(client *WebSocketClient, token string) bool {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	session, ok := s.sessions[token]
+	if !ok || session.expiresAt.IsZero() || time.Now().After(session.expiresAt) {
+		return false
+	}
+
+	session.expiresAt = time.Time{}
+	client.resumeToken = token
+	client.restoreState(session.filters, session.subscriptions)
+	return true
+}
+
+// openSession assigns a resume token to a client without one and sends it to the client
+func (s *Service) openSession(client *WebSocketClient, resumed bool) {
+	if client.resumeToken == "" {
+		client.resumeToken = uuid.New().String()
+
+		s.sessionsMu.Lock()
+		s.sessions[client.resumeToken] = &clientSession{}
+		s.sessionsMu.Unlock()
+	}
+
+	err := client.SendJSON(map[string]interface{}{
+		"type":        "session",
+		"resumeToken": client.resumeToken,
+		"resumed":     resumed,
+	})
+	if err != nil {
+		s.logger.Warn("Error sending session to client", "client", client.ID, "error", err)
+	}
+}
+
+// closeSession saves the client's state and starts the expiry countdown for its token
+func (s *Service) closeSession(client *WebSocketClient) {
+	if client.resumeToken == "" {
+		return
+	}
+
+	filters, subscriptions := client.state()
+
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+
+	s.sessions[client.resumeToken] = &clientSession{
+		filters:       filters,
+		subscriptions: subscriptions,
+		expiresAt:     time.Now().Add(s.config.ResumeTTL),
+	}
+}
+
+// expireSessions periodically removes sessions abandoned for longer than the TTL
+func (s *Service) expireSessions() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			s.sessionsMu.Lock()
+			var expired []*clientSession
+			for token, session := range s.sessions {
+				if !session.expiresAt.IsZero() && now.After(session.expiresAt) {
+					delete(s.sessions, token)
+					expired = append(expired, session)
+				}
+			}
+			s.sessionsMu.Unlock()
+
+			// Release the contract subscriptions the abandoned sessions held
+			for _, session := range expired {
+				for _, subscription := range session.subscriptions {
+					s.UnsubscribeFromContract(subscription.Contract, subscription.Events)
+				}
+			}
+		case <-s.quit:
+			return
+		}
+	}
+}
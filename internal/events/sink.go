@@ -0,0 +1,77 @@
+package events
+
+import (
+	"expvar"
+	"log/slog"
+	"sync/atomic"
+)
+
+// sinkMetrics exposes the events published to sinks and the failed publishes
+var sinkMetrics = expvar.NewMap("event_sinks")
+
+// EventSink receives every broadcast event, e.g. to forward it to a message
+// broker. Publish is called from the event handlers and must not block for
+// long; events it fails to publish are dropped.
+type EventSink interface {
+	// Publish publishes the JSON of an event of the given type
+	Publish(eventType string, eventJSON []byte) error
+	// Close flushes pending events and closes the sink
+	Close() error
+}
+
+// sinkPublisher publishes to a sink, logging when it starts and stops failing
+// rather than on every failed event
+type sinkPublisher struct {
+	sink    EventSink
+	logger  *slog.Logger
+	failing atomic.Bool
+}
+
+// publish publishes an event to the sink. Failures are logged and counted,
+// never returned, so a broker outage doesn't affect WebSocket clients.
+func (p *sinkPublisher) publish(eventType string, eventJSON []byte) {
+	if err := p.sink.Publish(eventType, eventJSON); err != nil {
+		sinkMetrics.Add("failed", 1)
+		if p.failing.CompareAndSwap(false, true) {
+			p.logger.Warn("Failed to publish event to sink, dropping events until it recovers", "error", err)
+		}
+		return
+	}
+
+	sinkMetrics.Add("published", 1)
+	if p.failing.CompareAndSwap(true, false) {
+		p.logger.Info("Event sink recovered")
+	}
+}
+
+// AddSink registers a sink that receives every broadcast event from now on.
+// The sink is closed when the service stops.
+func (s *Service) AddSink(sink EventSink) {
+	s.sinksMu.Lock()
+	defer s.sinksMu.Unlock()
+
+	s.sinks = append(s.sinks, &sinkPublisher{sink: sink, logger: s.logger})
+}
+
+// publishToSinks fans an event out to the registered sinks
+func (s *Service) publishToSinks(eventType string, eventJSON []byte) {
+	s.sinksMu.RLock()
+	defer s.sinksMu.RUnlock()
+
+	for _, sink := range s.sinks {
+		sink.publish(eventType, eventJSON)
+	}
+}
+
+// closeSinks closes the registered sinks
+func (s *Service) closeSinks() {
+	s.sinksMu.Lock()
+	defer s.sinksMu.Unlock()
+
+	for _, sink := range s.sinks {
+		if err := sink.sink.Close(); err != nil {
+			s.logger.Warn("Error closing event sink", "error", err)
+		}
+	}
+	s.sinks = nil
+}
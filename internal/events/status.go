@@ -0,0 +1,38 @@
+package events
+
+import "runtime"
+
+// Status is a snapshot of what the event service is doing
+type Status struct {
+	Clients               int // Connected WebSocket clients
+	Streams               int // Open server-sent event streams
+	ContractSubscriptions []ContractSubscriptionInfo
+	TransactionFilters    []FilterEntry
+	LatestBlock           uint64 // Highest block emitted; 0 before the first one
+	CatchUpRemaining      uint64
+	QueueDepth            int // Handler invocations waiting for a worker
+	Workers               int
+	Goroutines            int
+	Pause                 PauseState
+}
+
+// Status returns a snapshot of the connected clients, subscriptions and
+// processing state of the service
+func (s *Service) Status() Status {
+	s.mu.RLock()
+	clients, streams := len(s.clients), len(s.streams)
+	s.mu.RUnlock()
+
+	return Status{
+		Clients:               clients,
+		Streams:               streams,
+		ContractSubscriptions: s.listener.ContractSubscriptions(),
+		TransactionFilters:    s.TransactionFilters(),
+		LatestBlock:           s.head.latest(),
+		CatchUpRemaining:      s.listener.CatchUpRemaining(),
+		QueueDepth:            len(s.listener.pool.jobs),
+		Workers:               s.listener.pool.workers,
+		Goroutines:            runtime.NumGoroutine(),
+		Pause:                 s.pause.state(),
+	}
+}
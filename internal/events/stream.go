@@ -0,0 +1,87 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// StreamSubscriber receives broadcast events for a long-lived HTTP response,
+// as an alternative to a WebSocket connection
+type StreamSubscriber struct {
+	ID      string
+	filters EventFilters
+	events  chan []byte
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Events returns the channel of JSON-encoded events
+func (st *StreamSubscriber) Events() <-chan []byte {
+	return st.events
+}
+
+// Done returns a channel that's closed when the stream must end
+func (st *StreamSubscriber) Done() <-chan struct{} {
+	return st.done
+}
+
+// send queues an event, ending the stream if the reader can't keep up
+func (st *StreamSubscriber) send(message []byte) {
+	select {
+	case st.events <- message:
+		clientMetrics.Add("messages_sent", 1)
+	case <-st.done:
+	default:
+		clientMetrics.Add("closed_buffer_full", 1)
+		st.close()
+	}
+}
+
+// close ends the stream
+func (st *StreamSubscriber) close() {
+	st.once.Do(func() {
+		close(st.done)
+	})
+}
+
+// OpenStream registers a stream receiving the broadcast events that pass the filters
+func (s *Service) OpenStream(filters EventFilters) *StreamSubscriber {
+	stream := &StreamSubscriber{
+		ID:      uuid.New().String(),
+		filters: filters,
+		events:  make(chan []byte, 256),
+		done:    make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.streams[stream.ID] = stream
+	return stream
+}
+
+// CloseStream unregisters a stream
+func (s *Service) CloseStream(stream *StreamSubscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream.close()
+	delete(s.streams, stream.ID)
+}
+
+// closeStreams ends all open streams
+func (s *Service) closeStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, stream := range s.streams {
+		stream.close()
+		delete(s.streams, id)
+	}
+}
+
+// RequiresAuth reports whether clients must authenticate to receive events
+func (s *Service) RequiresAuth() bool {
+	return s.config.RequireAuth
+}
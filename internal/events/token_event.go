@@ -0,0 +1,101 @@
+package events
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// erc20EventsABI declares the standard ERC20 events, which are decoded
+// whether or not the token's ABI is registered
+const erc20EventsABI = `[
+	{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"to","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]},
+	{"type":"event","name":"Approval","inputs":[
+		{"name":"owner","type":"address","indexed":true},
+		{"name":"spender","type":"address","indexed":true},
+		{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+// erc20Events is the parsed erc20EventsABI
+var erc20Events = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(erc20EventsABI))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// TokenTransfer is a decoded ERC20 Transfer event, with the value in base units
+type TokenTransfer struct {
+	From  string          `json:"from"`
+	To    string          `json:"to"`
+	Value ethereum.Amount `json:"value"`
+}
+
+// TokenApproval is a decoded ERC20 Approval event, with the allowance in base units
+type TokenApproval struct {
+	Owner   string          `json:"owner"`
+	Spender string          `json:"spender"`
+	Value   ethereum.Amount `json:"value"`
+}
+
+// decodeTokenEvent matches a log against the standard ERC20 events and
+// decodes its arguments. Logs with another topic layout, like ERC721
+// events whose token id is indexed, don't match.
+func decodeTokenEvent(vLog types.Log) (*abi.Event, map[string]interface{}, bool) {
+	if len(vLog.Topics) == 0 {
+		return nil, nil, false
+	}
+	event, err := erc20Events.EventByID(vLog.Topics[0])
+	if err != nil {
+		return nil, nil, false
+	}
+	args, err := ethereum.DecodeEventArgs(*event, &vLog)
+	if err != nil {
+		return nil, nil, false
+	}
+	return event, args, true
+}
+
+// tokenEventName returns the name of the standard ERC20 event of a log, or an empty string
+func tokenEventName(vLog types.Log) string {
+	if event, _, ok := decodeTokenEvent(vLog); ok {
+		return event.Name
+	}
+	return ""
+}
+
+// setTokenEvent fills in the structured form of ERC20 Transfer and Approval
+// logs, and their name and arguments when the token's ABI isn't registered
+func (d *ContractEventData) setTokenEvent(vLog types.Log) {
+	event, args, ok := decodeTokenEvent(vLog)
+	if !ok {
+		return
+	}
+
+	addressArg := func(name string) string {
+		address, _ := args[name].(common.Address)
+		return address.Hex()
+	}
+	// Decoded integers are decimal strings
+	decimal, _ := args["value"].(string)
+	value, _ := new(big.Int).SetString(decimal, 10)
+
+	switch event.Name {
+	case "Transfer":
+		d.Transfer = &TokenTransfer{From: addressArg("from"), To: addressArg("to"), Value: ethereum.NewAmount(value)}
+	case "Approval":
+		d.Approval = &TokenApproval{Owner: addressArg("owner"), Spender: addressArg("spender"), Value: ethereum.NewAmount(value)}
+	}
+	if d.Event == "" {
+		d.Event = event.Name
+		d.Args = args
+	}
+}
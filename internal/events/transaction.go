@@ -4,23 +4,32 @@ import (
 	"context"
 	"math/big"
 	"strings"
+	"sync"
 
+	"github.com/em/go-web3/internal/ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
 )
 
 // TransactionInfo represents enriched information about a transaction
 type TransactionInfo struct {
-	Transaction    *types.Transaction
-	BlockHash      common.Hash
-	BlockNumber    uint64
-	From           common.Address
-	To             common.Address
-	Value          *big.Int
-	GasPrice       *big.Int
-	Gas            uint64
-	Input          []byte
-	IsContractCall bool
+	Transaction        *types.Transaction
+	BlockHash          common.Hash
+	BlockNumber        uint64
+	TransactionIndex   uint // Position in the block; zero for pending transactions
+	From               common.Address
+	To                 common.Address // Zero for contract creations
+	Value              *big.Int
+	GasPrice           *big.Int
+	Gas                uint64
+	Input              []byte
+	IsContractCall     bool
+	IsContractCreation bool
+	ContractAddress    common.Address // Address of the deployed contract for contract creations
+	IsPending          bool           // Set for mempool transactions, which have no block hash or number yet
+	Receipt            *types.Receipt // Set for mined transactions when receipts are enabled
 }
 
 // TransactionHandlerFunc defines a function that processes transaction info
@@ -28,11 +37,14 @@ type TransactionHandlerFunc func(info *TransactionInfo)
 
 // TransactionFilter defines criteria to filter transactions
 type TransactionFilter struct {
-	FromAddress     *common.Address
-	ToAddress       *common.Address
-	MinValue        *big.Int
-	OnlyContractTxs bool
-	MethodSignature string
+	Address               *common.Address // Matches transactions sent from or to the address
+	FromAddress           *common.Address
+	ToAddress             *common.Address
+	MinValue              *big.Int
+	OnlyContractTxs       bool
+	OnlyContractCreations bool
+	OnlySuccessful        bool // Matches mined transactions with a successful receipt; requires receipts
+	MethodSignature       string
 }
 
 // TransactionProcessor handles processing and filtering of transactions
@@ -41,7 +53,70 @@ type TransactionProcessor struct {
 	ctx      context.Context
 	cancel   context.CancelFunc
 	handlers []TransactionHandlerFunc
-	filter   *TransactionFilter
+	watchers []TransactionHandlerFunc // Called for every transaction, before filtering
+	filters  map[string]*TransactionFilter
+	order    []string // Filter ids in insertion order
+	filterMu sync.RWMutex
+	seen     *seenTransactions
+
+	// Fetch receipts of mined transactions, one batched call per block
+	withReceipts bool
+
+	// Registers the listener handlers only once
+	startOnce sync.Once
+}
+
+// FilterEntry is an active transaction filter with its id
+type FilterEntry struct {
+	ID     string
+	Filter *TransactionFilter
+}
+
+// txState records how far a transaction has been delivered
+type txState int
+
+const (
+	txStatePending txState = iota + 1
+	txStateMined
+)
+
+// maxSeenTransactions bounds the number of hashes remembered for deduplication
+const maxSeenTransactions = 50000
+
+// seenTransactions remembers recently delivered transactions by hash so the same
+// transaction isn't delivered twice by the pending or mined feeds
+type seenTransactions struct {
+	states map[common.Hash]txState
+	order  []common.Hash
+	mu     sync.Mutex
+}
+
+// newSeenTransactions creates an empty set of seen transactions
+func newSeenTransactions() *seenTransactions {
+	return &seenTransactions{
+		states: make(map[common.Hash]txState),
+	}
+}
+
+// advance moves a transaction to the given state, returning false if it
+// was already delivered in that state or a later one
+func (s *seenTransactions) advance(hash common.Hash, state txState) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.states[hash]
+	if ok && current >= state {
+		return false
+	}
+	if !ok {
+		s.order = append(s.order, hash)
+		if len(s.order) > maxSeenTransactions {
+			delete(s.states, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	s.states[hash] = state
+	return true
 }
 
 // NewTransactionProcessor creates a new transaction processor
@@ -52,13 +127,52 @@ func NewTransactionProcessor(listener *Listener) *TransactionProcessor {
 		ctx:      ctx,
 		cancel:   cancel,
 		handlers: []TransactionHandlerFunc{},
+		filters:  make(map[string]*TransactionFilter),
+		seen:     newSeenTransactions(),
 	}
 }
 
-// WithFilter sets a filter for transactions
-func (p *TransactionProcessor) WithFilter(filter *TransactionFilter) *TransactionProcessor {
-	p.filter = filter
-	return p
+// AddFilter adds a filter for transactions and returns its id.
+// Once filters are added, only transactions matching at least one of them are processed.
+func (p *TransactionProcessor) AddFilter(filter *TransactionFilter) string {
+	p.filterMu.Lock()
+	defer p.filterMu.Unlock()
+
+	id := uuid.New().String()
+	p.filters[id] = filter
+	p.order = append(p.order, id)
+	return id
+}
+
+// RemoveFilter removes the filter with the given id, reporting whether it existed
+func (p *TransactionProcessor) RemoveFilter(id string) bool {
+	p.filterMu.Lock()
+	defer p.filterMu.Unlock()
+
+	if _, ok := p.filters[id]; !ok {
+		return false
+	}
+
+	delete(p.filters, id)
+	for i, existing := range p.order {
+		if existing == id {
+			p.order = append(p.order[:i], p.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Filters returns the active filters in the order they were added
+func (p *TransactionProcessor) Filters() []FilterEntry {
+	p.filterMu.RLock()
+	defer p.filterMu.RUnlock()
+
+	entries := make([]FilterEntry, 0, len(p.order))
+	for _, id := range p.order {
+		entries = append(entries, FilterEntry{ID: id, Filter: p.filters[id]})
+	}
+	return entries
 }
 
 // OnTransaction adds a handler for transactions
@@ -67,45 +181,135 @@ func (p *TransactionProcessor) OnTransaction(handler TransactionHandlerFunc) *Tr
 	return p
 }
 
-// Start begins processing transactions
+// OnAnyTransaction adds a handler for every transaction, whether or not it
+// matches the processor's filters
+func (p *TransactionProcessor) OnAnyTransaction(handler TransactionHandlerFunc) *TransactionProcessor {
+	p.watchers = append(p.watchers, handler)
+	return p
+}
+
+// WithReceipts enables fetching the receipt of each mined transaction.
+// Receipts are fetched per block, at the cost of an extra RPC call per block.
+func (p *TransactionProcessor) WithReceipts(enabled bool) *TransactionProcessor {
+	p.withReceipts = enabled
+	return p
+}
+
+// Start begins processing transactions. Its handlers are registered with the
+// listener rather than a node subscription, so they keep receiving events
+// after the listener re-establishes a dropped subscription. Calling Start
+// again has no effect.
 func (p *TransactionProcessor) Start() {
-	p.listener.SubscribeToNewTransactions(func(tx *types.Transaction, blockHash common.Hash, blockNumber uint64) {
-		// Create transaction info
-		info := &TransactionInfo{
-			Transaction: tx,
-			BlockHash:   blockHash,
-			BlockNumber: blockNumber,
-			Value:       tx.Value(),
-			GasPrice:    tx.GasPrice(),
-			Gas:         tx.Gas(),
-			Input:       tx.Data(),
+	p.startOnce.Do(p.registerHandlers)
+}
+
+// registerHandlers subscribes the processor to the listener's transaction events
+func (p *TransactionProcessor) registerHandlers() {
+	if p.withReceipts {
+		p.listener.Subscribe(EventTypeNewBlock, func(event Event) {
+			if block, ok := event.Data.(*types.Block); ok {
+				p.processBlock(block)
+			}
+		})
+	} else {
+		// Transactions of a block arrive in index order
+		p.listener.Subscribe(EventTypeNewTransaction, func(event Event) {
+			tx, ok := event.Data.(*types.Transaction)
+			if !ok || !p.seen.advance(tx.Hash(), txStateMined) {
+				return
+			}
+			info := p.buildInfo(tx, event.BlockHash, event.BlockNum, false)
+			info.TransactionIndex = event.TxIndex
+			p.process(info)
+		})
+	}
+
+	// Pending transactions are only delivered if the mempool feed is enabled
+	p.listener.SubscribeToPendingTransactions(func(tx *types.Transaction, _ common.Hash, _ uint64) {
+		if !p.seen.advance(tx.Hash(), txStatePending) {
+			return
 		}
+		p.process(p.buildInfo(tx, common.Hash{}, 0, true))
+	})
+}
 
-		// Set To address if available (will be nil for contract creation)
-		if tx.To() != nil {
-			info.To = *tx.To()
+// processBlock processes the transactions of a block with their receipts.
+// If the receipts can't be fetched the transactions are processed without them.
+func (p *TransactionProcessor) processBlock(block *types.Block) {
+	receipts, err := p.listener.client.GetBlockReceipts(p.ctx, block)
+	if err != nil {
+		p.listener.logger.Warn("Error getting block receipts", "block", block.NumberU64(), "error", err)
+	}
+
+	for i, tx := range block.Transactions() {
+		if !p.seen.advance(tx.Hash(), txStateMined) {
+			continue
+		}
+		info := p.buildInfo(tx, block.Hash(), block.NumberU64(), false)
+		info.TransactionIndex = uint(i)
+		if receipts != nil {
+			info.Receipt = receipts[i]
 		}
+		p.process(info)
+	}
+}
+
+// buildInfo creates the enriched transaction info
+func (p *TransactionProcessor) buildInfo(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, pending bool) *TransactionInfo {
+	// Create transaction info
+	info := &TransactionInfo{
+		Transaction: tx,
+		BlockHash:   blockHash,
+		BlockNumber: blockNumber,
+		Value:       tx.Value(),
+		GasPrice:    tx.GasPrice(),
+		Gas:         tx.Gas(),
+		Input:       tx.Data(),
+		IsPending:   pending,
+	}
+
+	// Set To address if available (will be nil for contract creation)
+	if tx.To() != nil {
+		info.To = *tx.To()
+	}
+
+	// Recover the sender; From stays zero for transactions with invalid signatures
+	from, err := ethereum.RecoverSender(tx, p.listener.client.ConfiguredChainID())
+	if err == nil {
+		info.From = from
+	} else {
+		p.listener.logger.Debug("Could not recover transaction sender", "error", err)
+	}
+
+	// Determine if this is a contract call (data length > 0)
+	info.IsContractCall = len(tx.Data()) > 0 && tx.To() != nil
 
-		// Try to determine From address if possible
-		// Note: Getting the sender requires the correct chain ID and might fail
-		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	// Contract creations deploy to an address derived from the sender and nonce
+	if tx.To() == nil {
+		info.IsContractCreation = true
 		if err == nil {
-			info.From = from
+			info.ContractAddress = crypto.CreateAddress(from, tx.Nonce())
 		}
+	}
 
-		// Determine if this is a contract call (data length > 0)
-		info.IsContractCall = len(tx.Data()) > 0 && tx.To() != nil
+	return info
+}
 
-		// Apply filter if set
-		if p.filter != nil && !p.matchesFilter(info) {
-			return
-		}
+// process applies the filters and calls the handlers
+func (p *TransactionProcessor) process(info *TransactionInfo) {
+	for _, watcher := range p.watchers {
+		watcher(info)
+	}
 
-		// Call all handlers
-		for _, handler := range p.handlers {
-			handler(info)
-		}
-	})
+	// Apply filters if set
+	if !p.matchesAnyFilter(info) {
+		return
+	}
+
+	// Call all handlers
+	for _, handler := range p.handlers {
+		handler(info)
+	}
 }
 
 // Stop stops processing transactions
@@ -113,30 +317,63 @@ func (p *TransactionProcessor) Stop() {
 	p.cancel()
 }
 
-// matchesFilter checks if a transaction matches the filter criteria
-func (p *TransactionProcessor) matchesFilter(info *TransactionInfo) bool {
+// matchesAnyFilter checks if a transaction matches at least one filter.
+// Without filters every transaction matches.
+func (p *TransactionProcessor) matchesAnyFilter(info *TransactionInfo) bool {
+	p.filterMu.RLock()
+	defer p.filterMu.RUnlock()
+
+	if len(p.filters) == 0 {
+		return true
+	}
+
+	for _, filter := range p.filters {
+		if filter.Matches(info) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks if a transaction matches the filter criteria
+func (f *TransactionFilter) Matches(info *TransactionInfo) bool {
+	// Check either side of the transaction
+	if f.Address != nil && info.From != *f.Address && info.To != *f.Address {
+		return false
+	}
+
 	// Check From address
-	if p.filter.FromAddress != nil && info.From != *p.filter.FromAddress {
+	if f.FromAddress != nil && info.From != *f.FromAddress {
 		return false
 	}
 
 	// Check To address
-	if p.filter.ToAddress != nil && info.To != *p.filter.ToAddress {
+	if f.ToAddress != nil && info.To != *f.ToAddress {
 		return false
 	}
 
 	// Check minimum value
-	if p.filter.MinValue != nil && info.Value.Cmp(p.filter.MinValue) < 0 {
+	if f.MinValue != nil && info.Value.Cmp(f.MinValue) < 0 {
 		return false
 	}
 
 	// Check if only contract transactions are wanted
-	if p.filter.OnlyContractTxs && !info.IsContractCall {
+	if f.OnlyContractTxs && !info.IsContractCall {
+		return false
+	}
+
+	// Check if only contract deployments are wanted
+	if f.OnlyContractCreations && !info.IsContractCreation {
+		return false
+	}
+
+	// Reverted transactions, and those without a receipt, are left out when only successful ones are wanted
+	if f.OnlySuccessful && (info.Receipt == nil || info.Receipt.Status != types.ReceiptStatusSuccessful) {
 		return false
 	}
 
 	// Check method signature if specified
-	if p.filter.MethodSignature != "" && !matchesMethodSignature(info.Input, p.filter.MethodSignature) {
+	if f.MethodSignature != "" && !matchesMethodSignature(info.Input, f.MethodSignature) {
 		return false
 	}
 
@@ -3,14 +3,32 @@ package events
 import (
 	"context"
 	"encoding/json"
+	"expvar"
 	"fmt"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/em/go-web3/internal/ethereum"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// clientMetrics exposes WebSocket delivery and disconnect counters
+var clientMetrics = expvar.NewMap("websocket_clients")
+
+// Reasons a WebSocket client was disconnected
+const (
+	CloseReasonClientClosed   = "client_closed"
+	CloseReasonBufferFull     = "send_buffer_full"
+	CloseReasonPingFailed     = "ping_failed"
+	CloseReasonAuthFailed     = "auth_failed"
+	CloseReasonAuthTimeout    = "auth_timeout"
+	CloseReasonServerShutdown = "server_shutdown"
+)
+
 // WebSocketClient represents a connected WebSocket client
 type WebSocketClient struct {
 	ID      string
@@ -20,36 +38,108 @@ type WebSocketClient struct {
 	cancel  context.CancelFunc
 	mu      sync.Mutex
 	filters EventFilters
+	logger  *slog.Logger
+
+	// Graceful shutdown of the writer
+	shutdown     chan struct{}
+	shutdownOnce sync.Once
+	writerDone   chan struct{}
+
+	// Teardown when the reader or writer stops, whichever comes first
+	unregister   func()
+	teardownOnce sync.Once
+	closeOnce    sync.Once
+
+	// Session state restored when reconnecting with a resume token
+	resumeToken   string
+	subscriptions []ContractSubscription
+	stateMu       sync.RWMutex
+
+	// Dedicated channel the client connected to, if any
+	channel *Channel
+
+	// Replay of the events missed while disconnected
+	replaySince *uint64
+	replaying   bool
+	replayHeld  []heldReplayEvent
+	replayMu    sync.Mutex
+
+	// Wallet authentication via signed challenge
+	challenge string
+	address   *common.Address
+
+	// Event batching for clients that opted in
+	batchWindow time.Duration
+	batch       [][]byte
+	batchMu     sync.Mutex
+
+	// Records events that failed to be delivered
+	deadLetters *deadLetterLog
+
+	// Addresses whose balance changes are pushed to the client
+	balances map[common.Address]*balanceWatch
+
+	// Pending transactions pushed to the client, then their confirmation
+	pending pendingWatches
+
+	// Limits on inbound messages and contract subscriptions
+	limiter          *messageLimiter
+	maxSubscriptions int
+
+	// Subscriptions whose past logs are being replayed
+	backfills  []*backfill
+	backfillMu sync.Mutex
+
+	// Connection statistics
+	connectedAt  time.Time
+	messagesSent atomic.Uint64
+	closeReason  string
 }
 
 // EventFilters holds filters for events the client is interested in
 type EventFilters struct {
 	EventTypes      []EventType
 	ContractAddress []string
+	Topics          [][]string // Log topics by position; an empty position matches any topic
+	Batch           bool       // Coalesce events over the batch window into JSON array frames
 }
 
 // NewWebSocketClient creates a new WebSocket client
-func NewWebSocketClient(conn *websocket.Conn) *WebSocketClient {
+func NewWebSocketClient(conn *websocket.Conn, logger *slog.Logger) *WebSocketClient {
 	ctx, cancel := context.WithCancel(context.Background())
+	id := uuid.New().String()
 	return &WebSocketClient{
-		ID:      uuid.New().String(),
-		conn:    conn,
-		send:    make(chan []byte, 256),
-		ctx:     ctx,
-		cancel:  cancel,
-		filters: EventFilters{},
+		ID:          id,
+		conn:        conn,
+		send:        make(chan []byte, 256),
+		ctx:         ctx,
+		cancel:      cancel,
+		filters:     EventFilters{},
+		logger:      logger.With("client", id),
+		shutdown:    make(chan struct{}),
+		writerDone:  make(chan struct{}),
+		connectedAt: time.Now(),
 	}
 }
 
 // Send sends a message to the client
 func (c *WebSocketClient) Send(message []byte) error {
+	// The select below may still pick the send case once the client is closed
+	if c.ctx.Err() != nil {
+		return fmt.Errorf("client connection closed")
+	}
+
 	select {
 	case c.send <- message:
+		c.messagesSent.Add(1)
+		clientMetrics.Add("messages_sent", 1)
 		return nil
 	case <-c.ctx.Done():
 		return fmt.Errorf("client connection closed")
 	default:
 		// Buffer full, close the connection
+		clientMetrics.Add("closed_buffer_full", 1)
+		c.SetCloseReason(CloseReasonBufferFull)
 		c.Close()
 		return fmt.Errorf("client send buffer full")
 	}
@@ -63,13 +153,73 @@ func (c *WebSocketClient) SendPing() error {
 	return c.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(10*time.Second))
 }
 
-// Close closes the client connection
+// Close cancels the client's context and closes its connection. Only the
+// first call has any effect.
 func (c *WebSocketClient) Close() {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		c.cancel()
+		c.conn.Close()
+	})
+}
+
+// teardown ends the client when its reader or writer stops: the client is
+// unregistered, and its context canceled so that the other goroutines stop too
+func (c *WebSocketClient) teardown() {
+	c.teardownOnce.Do(func() {
+		if c.unregister != nil {
+			c.unregister()
+		}
+		c.Close()
+	})
+}
+
+// SetCloseReason records why the client is being disconnected.
+// Only the first reason is kept.
+func (c *WebSocketClient) SetCloseReason(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeReason == "" {
+		c.closeReason = reason
+	}
+}
+
+// CloseReason returns why the client was disconnected, defaulting to the
+// client closing the connection itself
+func (c *WebSocketClient) CloseReason() string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.cancel()
-	c.conn.Close()
+	if c.closeReason == "" {
+		return CloseReasonClientClosed
+	}
+	return c.closeReason
+}
+
+// MessagesSent returns the number of messages queued to the client over its lifetime
+func (c *WebSocketClient) MessagesSent() uint64 {
+	return c.messagesSent.Load()
+}
+
+// Shutdown flushes the queued messages, sends a close frame and closes the
+// connection, waiting for the writer to finish until the context expires
+func (c *WebSocketClient) Shutdown(ctx context.Context) {
+	// Batched events are flushed right away rather than after the window
+	c.flushBatch()
+
+	c.shutdownOnce.Do(func() {
+		close(c.shutdown)
+	})
+
+	select {
+	case <-c.writerDone:
+	case <-ctx.Done():
+	}
+
+	c.Close()
 }
 
 // Done returns a channel that's closed when the client is done
@@ -77,12 +227,40 @@ func (c *WebSocketClient) Done() <-chan struct{} {
 	return c.ctx.Done()
 }
 
+// state returns a copy of the client's filters and contract subscriptions
+func (c *WebSocketClient) state() (EventFilters, []ContractSubscription) {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	subscriptions := make([]ContractSubscription, len(c.subscriptions))
+	copy(subscriptions, c.subscriptions)
+	return c.filters, subscriptions
+}
+
+// Accepts reports whether an event passes the client's filters and those of its channel
+func (c *WebSocketClient) Accepts(event Event) bool {
+	c.stateMu.RLock()
+	defer c.stateMu.RUnlock()
+
+	if c.channel != nil && !c.channel.Filters.Matches(event) {
+		return false
+	}
+	return c.filters.Matches(event)
+}
+
+// restoreState replaces the client's filters and contract subscriptions
+func (c *WebSocketClient) restoreState(filters EventFilters, subscriptions []ContractSubscription) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.filters = filters
+	c.subscriptions = subscriptions
+}
+
 // StartReading starts reading messages from the client
 func (c *WebSocketClient) StartReading(service *Service) {
 	go func() {
-		defer func() {
-			service.UnregisterClient(c.ID)
-		}()
+		defer c.teardown()
 
 		c.conn.SetReadLimit(512 * 1024) // 512KB
 		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
@@ -95,7 +273,7 @@ func (c *WebSocketClient) StartReading(service *Service) {
 			_, message, err := c.conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					fmt.Printf("WebSocket read error: %v\n", err)
+					c.logger.Warn("WebSocket read error", "error", err)
 				}
 				break
 			}
@@ -112,7 +290,8 @@ func (c *WebSocketClient) StartWriting() {
 		ticker := time.NewTicker(45 * time.Second)
 		defer func() {
 			ticker.Stop()
-			c.conn.Close()
+			close(c.writerDone)
+			c.teardown()
 		}()
 
 		for {
@@ -146,6 +325,18 @@ func (c *WebSocketClient) StartWriting() {
 				if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
 					return
 				}
+			case <-c.shutdown:
+				// Flush what is already queued, then say goodbye
+				for n := len(c.send); n > 0; n-- {
+					c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+					if err := c.conn.WriteMessage(websocket.TextMessage, <-c.send); err != nil {
+						return
+					}
+				}
+				c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				c.conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"))
+				return
 			case <-c.ctx.Done():
 				return
 			}
@@ -157,7 +348,7 @@ func (c *WebSocketClient) StartWriting() {
 func (c *WebSocketClient) handleMessage(message []byte, service *Service) {
 	var msg map[string]interface{}
 	if err := json.Unmarshal(message, &msg); err != nil {
-		fmt.Printf("Error unmarshaling message: %v\n", err)
+		c.logger.Debug("Error unmarshaling message", "error", err)
 		return
 	}
 
@@ -167,7 +358,60 @@ func (c *WebSocketClient) handleMessage(message []byte, service *Service) {
 		return
 	}
 
+	// Reject messages over the client's rate limit
+	if !c.limiter.Allow() {
+		clientMetrics.Add("rate_limited", 1)
+		c.SendJSON(map[string]interface{}{
+			"type":    "error",
+			"request": msgType,
+			"error":   "rate limit exceeded",
+		})
+		return
+	}
+
+	// Clients must authenticate before anything else when auth is required
+	if msgType != "auth" && service.config.RequireAuth {
+		if _, ok := c.Address(); !ok {
+			c.SendJSON(map[string]interface{}{
+				"type":  "error",
+				"error": "authentication required",
+			})
+			return
+		}
+	}
+
 	switch msgType {
+	case "auth":
+		// Handle challenge response
+		signature, _ := msg["signature"].(string)
+		claimed, _ := msg["address"].(string)
+
+		address, err := c.authenticate(signature, claimed)
+		if err != nil {
+			c.logger.Info("WebSocket authentication failed", "error", err)
+			c.SendJSON(map[string]interface{}{
+				"type":    "auth",
+				"success": false,
+				"error":   err.Error(),
+			})
+			if service.config.RequireAuth {
+				c.SetCloseReason(CloseReasonAuthFailed)
+				service.UnregisterClient(c.ID)
+			}
+			return
+		}
+
+		c.SendJSON(map[string]interface{}{
+			"type":    "auth",
+			"success": true,
+			"address": address.Hex(),
+		})
+
+		// Channel subscriptions wait for authentication when it is required
+		if service.config.RequireAuth {
+			c.joinChannel(service)
+		}
+
 	case "subscribe":
 		// Handle subscription request
 		if contract, ok := msg["contract"].(string); ok {
@@ -179,11 +423,143 @@ func (c *WebSocketClient) handleMessage(message []byte, service *Service) {
 					}
 				}
 			}
-			service.SubscribeToContract(contract, events)
+			var err error
+			if fromBlock, ok := msg["fromBlock"].(float64); ok {
+				if fromBlock < 0 || fromBlock != float64(uint64(fromBlock)) {
+					err = fmt.Errorf("invalid fromBlock")
+				} else {
+					err = c.subscribeFrom(service, contract, events, uint64(fromBlock))
+				}
+			} else {
+				err = c.subscribe(service, contract, events)
+			}
+			if err != nil {
+				c.SendJSON(map[string]interface{}{
+					"type":     "subscribe",
+					"success":  false,
+					"contract": contract,
+					"error":    err.Error(),
+				})
+			}
+		}
+
+		if batch, ok := msg["batch"].(bool); ok {
+			c.stateMu.Lock()
+			c.filters.Batch = batch
+			c.stateMu.Unlock()
+			if !batch {
+				c.flushBatch()
+			}
 		}
 
+	case "unsubscribe":
+		// Handle unsubscription request; without events, all of the contract's subscriptions end
+		contract, _ := msg["contract"].(string)
+		var events []string
+		eventsArray, hasEvents := msg["events"].([]interface{})
+		for _, e := range eventsArray {
+			if event, ok := e.(string); ok {
+				events = append(events, event)
+			}
+		}
+
+		removed := c.removeSubscriptions(contract, events, hasEvents)
+		for _, subscription := range removed {
+			service.UnsubscribeFromContract(subscription.Contract, subscription.Events)
+		}
+		c.SendJSON(map[string]interface{}{
+			"type":          "unsubscribe",
+			"success":       len(removed) > 0,
+			"contract":      contract,
+			"subscriptions": len(removed),
+		})
+
+	case "watch_balance", "unwatch_balance":
+		// Handle balance watch changes
+		addr, _ := msg["address"].(string)
+		if !common.IsHexAddress(addr) {
+			c.SendJSON(map[string]interface{}{
+				"type":    msgType,
+				"success": false,
+				"error":   "invalid address",
+			})
+			return
+		}
+		address := common.HexToAddress(addr)
+
+		if msgType == "unwatch_balance" {
+			c.unwatchBalance(address)
+			c.SendJSON(map[string]interface{}{
+				"type":    msgType,
+				"success": true,
+				"address": address.Hex(),
+			})
+			return
+		}
+
+		balance, err := service.listener.client.GetBalance(c.ctx, address.Hex())
+		if err == nil {
+			err = c.watchBalance(address, balance, service.config.MaxWatchedBalances)
+		}
+		if err != nil {
+			c.SendJSON(map[string]interface{}{
+				"type":    msgType,
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.SendJSON(map[string]interface{}{
+			"type":    msgType,
+			"success": true,
+			"address": address.Hex(),
+			"balance": ethereum.NewAmount(balance),
+		})
+
+	case "watch_pending":
+		// Handle watching pending transactions matching a filter
+		if !service.PendingTransactionsEnabled() {
+			c.SendJSON(map[string]interface{}{
+				"type":    msgType,
+				"success": false,
+				"error":   "pending transactions are disabled, set events.pendingTransactions to enable them",
+			})
+			return
+		}
+		filter, err := parsePendingFilter(msg)
+		var id string
+		if err == nil {
+			id, err = c.watchPending(filter, service.config.MaxPendingWatches)
+		}
+		if err != nil {
+			c.SendJSON(map[string]interface{}{
+				"type":    msgType,
+				"success": false,
+				"error":   err.Error(),
+			})
+			return
+		}
+		c.SendJSON(map[string]interface{}{
+			"type":    msgType,
+			"success": true,
+			"id":      id,
+		})
+
+	case "unwatch_pending":
+		// Handle removing a pending transaction watch; without an id, all of them are removed
+		id, _ := msg["id"].(string)
+		removed := c.unwatchPending(id)
+		c.SendJSON(map[string]interface{}{
+			"type":    msgType,
+			"success": removed > 0,
+			"watches": removed,
+		})
+
 	case "filter":
 		// Handle filter update
+		c.stateMu.Lock()
+		defer c.stateMu.Unlock()
+
 		if eventTypes, ok := msg["eventTypes"].([]interface{}); ok {
 			var types []EventType
 			for _, t := range eventTypes {
@@ -203,5 +579,16 @@ func (c *WebSocketClient) handleMessage(message []byte, service *Service) {
 			}
 			c.filters.ContractAddress = addresses
 		}
+
+		if topics, ok := msg["topics"].([]interface{}); ok {
+			c.filters.Topics = parseTopicFilter(topics)
+		}
+
+		if batch, ok := msg["batch"].(bool); ok {
+			c.filters.Batch = batch
+			if !batch {
+				c.flushBatch()
+			}
+		}
 	}
 }
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// connectTestClient upgrades a connection to a test server and returns the
+// server side as a WebSocketClient, along with the peer's connection
+func connectTestClient(t *testing.T) (*WebSocketClient, *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	peer, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { peer.Close() })
+
+	conn := <-conns
+	return NewWebSocketClient(conn, slog.New(slog.NewTextHandler(io.Discard, nil))), peer
+}
+
+// waitForGoroutines waits until no more than n goroutines are running
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Fatalf("%d goroutines running, want at most %d:\n%s",
+				runtime.NumGoroutine(), n, buf[:runtime.Stack(buf, true)])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWriteErrorTearsDownClient(t *testing.T) {
+	client, _ := connectTestClient(t)
+
+	var unregistered atomic.Int32
+	client.unregister = func() { unregistered.Add(1) }
+
+	// Let the upgrade handler return before counting
+	time.Sleep(50 * time.Millisecond)
+	baseline := runtime.NumGoroutine()
+
+	client.StartReading(nil)
+	client.StartWriting()
+
+	// Writes fail from now on, while the reader is still waiting for a frame
+	if err := client.conn.UnderlyingConn().(*net.TCPConn).CloseWrite(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Send([]byte(`{"type":"new_block"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-client.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("client context not canceled after a write error")
+	}
+	select {
+	case <-client.writerDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer did not stop after a write error")
+	}
+
+	waitForGoroutines(t, baseline)
+	if n := unregistered.Load(); n != 1 {
+		t.Errorf("client unregistered %d times, want 1", n)
+	}
+	if err := client.Send([]byte(`{}`)); err == nil {
+		t.Error("Send succeeded on a torn down client")
+	}
+}
@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/em/go-web3/internal/config"
+)
+
+// New creates a leveled logger from the log configuration
+func New(cfg *config.LogConfig) *slog.Logger {
+	return NewWithWriter(cfg, os.Stdout)
+}
+
+// NewWithWriter creates a leveled logger writing to w
+func NewWithWriter(cfg *config.LogConfig, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		Level: ParseLevel(cfg.Level),
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(cfg.Format, "json") {
+		handler = slog.NewJSONHandler(w, opts)
+	} else {
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	return slog.New(handler)
+}
+
+// ParseLevel converts a level name (debug, info, warn, error) to a slog level.
+// Unknown names default to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}